@@ -0,0 +1,201 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+var (
+	errVersionCodeOverflow  = errors.New("version code exceeds the Google Play limit")
+	errVersionCodeBackwards = errors.New("version code is lower than the one of the last tag")
+	errInvalidVersionCode   = errors.New("invalid version code")
+)
+
+const (
+	versionCodeSemver      = "semver"
+	versionCodeSemverBuild = "semver_build"
+	versionCodeBuildNumber = "build_number"
+
+	// maxVersionCode is the highest versionCode accepted by Google Play
+	maxVersionCode = 2100000000
+
+	defaultAndroidPropertiesFile = "version.properties"
+)
+
+// Patterns of the versionCode and versionName of the Groovy and Kotlin gradle scripts
+const (
+	gradleVersionCodePattern = `^\s*versionCode\s*=?\s*(\d+)`
+	gradleVersionNamePattern = `^\s*versionName\s*=?\s*["']((?:[^"'\\]|\\.)*)["']`
+)
+
+// saveAndroidFiles writes the versionCode and versionName to a properties file and to the gradle scripts
+func saveAndroidFiles(config *Config, buildInfo *BuildInfo) error {
+	android := config.Android
+
+	versionCode, err := android.versionCode(buildInfo)
+	if err != nil {
+		return err
+	}
+
+	if err := android.checkLastTagVersionCode(config, buildInfo, versionCode); err != nil {
+		return err
+	}
+
+	versionNameTemplate := android.VersionName
+	if versionNameTemplate == "" {
+		versionNameTemplate = "{{ .Version }}"
+	}
+
+	versionName, err := renderTemplate(versionNameTemplate, buildInfo)
+	if err != nil {
+		return fmt.Errorf("failed to render version name: %w", err)
+	}
+
+	propertiesFile := android.PropertiesFile
+	if propertiesFile == "" && len(android.GradleFiles) == 0 {
+		propertiesFile = defaultAndroidPropertiesFile
+	}
+
+	if propertiesFile != "" {
+		content := "# Generated by ci-info\n" +
+			"versionCode=" + strconv.Itoa(versionCode) + "\n" +
+			"versionName=" + propertiesEscape(versionName, false) + "\n"
+
+		if err := config.writer().WriteFile(config.outputPath(propertiesFile), []byte(content), 0); err != nil {
+			return fmt.Errorf("could not write %s: %w", propertiesFile, err)
+		}
+	}
+
+	for _, gradleFile := range android.GradleFiles {
+		if err := updateGradleFile(config, path.Join(config.Directory, gradleFile), versionCode, versionName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// versionCode encodes the version as an integer: major*10000 + minor*100 + patch by default
+func (a *ConfigAndroid) versionCode(buildInfo *BuildInfo) (int, error) {
+	v, err := parseVersion(buildInfo.VersionCore)
+	if err != nil {
+		return 0, err
+	}
+
+	var code int
+
+	switch a.VersionCode {
+	case "", versionCodeSemver:
+		if code, err = semverVersionCode(v); err != nil {
+			return 0, err
+		}
+	case versionCodeSemverBuild:
+		if code, err = semverVersionCode(v); err != nil {
+			return 0, err
+		}
+
+		build, _ := strconv.Atoi(buildInfo.VersionBuild)
+		if build > 999 {
+			return 0, fmt.Errorf("%w: the build number %d doesn't fit in 3 digits", errInvalidVersionCode, build)
+		}
+
+		code = code*1000 + build
+	case versionCodeBuildNumber:
+		code, _ = strconv.Atoi(buildInfo.VersionBuild)
+	default:
+		// Any template rendering an integer
+		rendered, err := renderTemplate(a.VersionCode, buildInfo)
+		if err != nil {
+			return 0, fmt.Errorf("failed to render version code: %w", err)
+		}
+
+		if code, err = strconv.Atoi(strings.TrimSpace(rendered)); err != nil {
+			return 0, fmt.Errorf("%w: %q", errInvalidVersionCode, rendered)
+		}
+	}
+
+	code += a.VersionCodeOffset
+
+	switch {
+	case code <= 0:
+		return 0, fmt.Errorf("%w: %d, it must be positive", errInvalidVersionCode, code)
+	case code > maxVersionCode:
+		return 0, fmt.Errorf("%w: %d > %d", errVersionCodeOverflow, code, maxVersionCode)
+	}
+
+	return code, nil
+}
+
+// semverVersionCode returns major*10000 + minor*100 + patch, which only orders the versions if the minor and
+// the patch have 2 digits at most
+func semverVersionCode(v *semVersion) (int, error) {
+	if v.Minor > 99 || v.Patch > 99 {
+		return 0, fmt.Errorf("%w: the minor and patch of %s must be lower than 100", errInvalidVersionCode, v.Core())
+	}
+
+	return v.Major*10000 + v.Minor*100 + v.Patch, nil
+}
+
+// checkLastTagVersionCode fails if the version code of the last tag, without any build number, is higher than
+// the current one. The version of the tag is extracted with the tag pattern, or is the tag itself without "v".
+func (a *ConfigAndroid) checkLastTagVersionCode(config *Config, buildInfo *BuildInfo, versionCode int) error {
+	if buildInfo.GitLastTag == "" {
+		return nil
+	}
+
+	lastVersion := normalizeVersion(buildInfo.GitLastTag)
+
+	if config.InputVersionTag.Pattern != "" {
+		var err error
+		if lastVersion, err = getVersionFromContent(buildInfo.GitLastTag, config.InputVersionTag.Pattern); err != nil {
+			return nil //nolint:nilerr // The tags that aren't versions are ignored
+		}
+	}
+
+	last := *buildInfo
+	last.VersionDeclared = lastVersion
+	last.Version = lastVersion
+	last.BuildNumber = ""
+	last.CIBuildNumber = ""
+	last.loadNumericVersion()
+
+	lastCode, err := a.versionCode(&last)
+	if err != nil {
+		return nil //nolint:nilerr // An invalid last version can't be compared
+	}
+
+	if lastCode > versionCode {
+		return fmt.Errorf("%w: %d < %d (%s)", errVersionCodeBackwards, versionCode, lastCode, buildInfo.GitLastTag)
+	}
+
+	return nil
+}
+
+// updateGradleFile replaces the versionCode and versionName of a build.gradle or build.gradle.kts file
+func updateGradleFile(config *Config, fileName string, versionCode int, versionName string) error {
+	content, err := config.writer().ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	updated, err := replacePattern(string(content), gradleVersionCodePattern, strconv.Itoa(versionCode))
+	if err != nil {
+		return fmt.Errorf("could not update %s: %w", fileName, err)
+	}
+
+	// Both Groovy and Kotlin accept these escape sequences, in single and double quotes
+	if updated, err = replacePattern(updated, gradleVersionNamePattern, kotlinString(versionName)); err != nil {
+		return fmt.Errorf("could not update %s: %w", fileName, err)
+	}
+
+	if updated == string(content) {
+		return nil
+	}
+
+	log.Debug("Updating gradle file", "path", fileName)
+
+	return config.writer().WriteFile(fileName, []byte(updated), 0)
+}
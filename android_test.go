@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAndroidVersionCode(t *testing.T) {
+	a := require.New(t)
+	bi := &BuildInfo{Version: "1.2.3-main-6ea1772", CIBuildNumber: "45"}
+	bi.loadNumericVersion()
+
+	for encoding, expected := range map[string]int{
+		"":                       10203,
+		versionCodeSemver:        10203,
+		versionCodeSemverBuild:   10203045,
+		versionCodeBuildNumber:   45,
+		" {{ .VersionBuild }}\n": 45,
+	} {
+		code, err := (&ConfigAndroid{VersionCode: encoding}).versionCode(bi)
+		a.NoError(err, encoding)
+		a.Equal(expected, code, encoding)
+	}
+
+	code, err := (&ConfigAndroid{VersionCode: versionCodeBuildNumber, VersionCodeOffset: 1000}).versionCode(bi)
+	a.NoError(err)
+	a.Equal(1045, code)
+
+	_, err = (&ConfigAndroid{VersionCode: "{{ .Version }}"}).versionCode(bi)
+	a.ErrorIs(err, errInvalidVersionCode)
+
+	_, err = (&ConfigAndroid{VersionCode: versionCodeBuildNumber}).versionCode(&BuildInfo{VersionCore: "1.2.3", VersionBuild: "0"})
+	a.ErrorIs(err, errInvalidVersionCode)
+
+	// The minor would overlap the major
+	_, err = (&ConfigAndroid{}).versionCode(&BuildInfo{VersionCore: "1.100.0"})
+	a.ErrorIs(err, errInvalidVersionCode)
+
+	_, err = (&ConfigAndroid{VersionCode: versionCodeSemverBuild}).versionCode(&BuildInfo{VersionCore: "2100.0.0"})
+	a.ErrorIs(err, errVersionCodeOverflow)
+
+	_, err = (&ConfigAndroid{VersionCode: versionCodeBuildNumber}).versionCode(
+		&BuildInfo{VersionCore: "1.2.3", VersionBuild: "7352361789"},
+	)
+	a.ErrorIs(err, errVersionCodeOverflow)
+}
+
+func TestAndroidLastTag(t *testing.T) {
+	a := require.New(t)
+	config := &Config{InputVersionTag: ConfigVersionInputTag{Pattern: `^v?([0-9.]+)$`}, Android: &ConfigAndroid{}}
+	bi := &BuildInfo{VersionCore: "1.2.0", GitLastTag: "v1.3.0"}
+
+	a.ErrorIs(config.Android.checkLastTagVersionCode(config, bi, 10200), errVersionCodeBackwards)
+	a.NoError(config.Android.checkLastTagVersionCode(config, bi, 10300))
+
+	// The tags that aren't versions are ignored
+	bi.GitLastTag = "nightly"
+	a.NoError(config.Android.checkLastTagVersionCode(config, bi, 10200))
+
+	// The build number of the last tag isn't known
+	config.Android.VersionCode = versionCodeBuildNumber
+	bi.GitLastTag = "v1.3.0"
+	a.NoError(config.Android.checkLastTagVersionCode(config, bi, 45))
+
+	// Without a tag pattern, the version comes from a file or an environment variable and the tag is the version
+	config = &Config{Android: &ConfigAndroid{}}
+	a.ErrorIs(config.Android.checkLastTagVersionCode(config, bi, 10200), errVersionCodeBackwards)
+	a.NoError(config.Android.checkLastTagVersionCode(config, bi, 10300))
+
+	bi.GitLastTag = "nightly"
+	a.NoError(config.Android.checkLastTagVersionCode(config, bi, 10200))
+}
+
+func TestMainAndroid(t *testing.T) {
+	a := require.New(t)
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, ".ci-info.json")
+
+	t.Setenv("APP_VERSION", "1.4.2")
+	t.Setenv("APP_NAME", `1.4.2 "$beta"`)
+
+	a.NoError(os.MkdirAll(filepath.Join(dir, "app"), 0750))
+	a.NoError(os.WriteFile(filepath.Join(dir, "app", "build.gradle"), []byte(
+		"android {\n    defaultConfig {\n        versionCode 1\n        versionName '1.0'\n    }\n}\n",
+	), 0600))
+	a.NoError(os.WriteFile(filepath.Join(dir, "app", "build.gradle.kts"), []byte(
+		"android {\n    defaultConfig {\n        versionCode = 1\n        versionName = \"1.0\"\n    }\n}\n",
+	), 0600))
+	a.NoError(os.WriteFile(configFile, []byte(`{
+		"version_input_env_var": {"env_var": "APP_VERSION", "pattern": "^(.+)$"},
+		"env_vars": ["APP_NAME"],
+		"android": {
+			"version_name": "{{ env \"APP_NAME\" }}",
+			"properties_file": "app/version.properties",
+			"gradle_files": ["app/build.gradle", "app/build.gradle.kts"]
+		}
+	}`), 0600))
+
+	a.NoError(runMain([]string{"-c", configFile}))
+
+	for fileName, expected := range map[string]string{
+		"app/version.properties": "# Generated by ci-info\nversionCode=10402\nversionName=1.4.2 \"$beta\"\n",
+		"app/build.gradle":       "android {\n    defaultConfig {\n        versionCode 10402\n        versionName '1.4.2 \\\"\\$beta\\\"'\n    }\n}\n",
+		"app/build.gradle.kts":   "android {\n    defaultConfig {\n        versionCode = 10402\n        versionName = \"1.4.2 \\\"\\$beta\\\"\"\n    }\n}\n",
+	} {
+		content, err := os.ReadFile(filepath.Join(dir, fileName)) //nolint:gosec
+		a.NoError(err)
+		a.Equal(expected, string(content))
+	}
+
+	a.NoError(os.WriteFile(filepath.Join(dir, "app", "build.gradle"), []byte("android {}\n"), 0600))
+	a.ErrorIs(runMain([]string{"-c", configFile}), errPatternNotFound)
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"reflect"
 	"regexp"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -13,6 +16,9 @@ type BuildInfo struct {
 	CIInfoVersion      string `json:"ci_info_version"`
 	VersionDeclared    string `json:"-"`
 	Version            string `json:"version,omitempty"`
+	VersionCore        string `json:"-"`
+	VersionBuild       string `json:"-"`
+	VersionNumeric     string `json:"-"`
 	GitCommitHash      string `json:"git_hash,omitempty"`
 	GitCommitHashShort string `json:"-"`
 	GitCommitDate      string `json:"git_date,omitempty"`
@@ -23,14 +29,50 @@ type BuildInfo struct {
 	GitRef             string `json:"-"`
 	GitSmartRef        string `json:"-"`
 	GitLastTag         string `json:"-"`
+	GitRepositoryURL   string `json:"git_repository_url,omitempty"`
 	BuildDate          string `json:"build_date,omitempty"`
 	BuildHost          string `json:"build_host,omitempty"`
 	BuildUser          string `json:"build_user,omitempty"`
 	CISolution         string `json:"ci_solution,omitempty"`
 	CIBuildNumber      string `json:"ci_build_number,omitempty"`
+	CIBuildURL         string `json:"ci_build_url,omitempty"`
+	BuildNumber        string `json:"build_number,omitempty"`
 	PackageManager     string `json:"package_manager,omitempty"`
+	PackageName        string `json:"package_name,omitempty"`
+	PackageVersion     string `json:"package_version,omitempty"`
+	ChartVersion       string `json:"chart_version,omitempty"`
+	ChartAppVersion    string `json:"chart_app_version,omitempty"`
+
+	// Custom contains the variables of the config, Env the environment variables it allows
+	Custom map[string]string `json:"custom,omitempty"`
+	Env    map[string]string `json:"-"`
+
+	// versionSources contains all the versions that were found, to check their consistency
+	versionSources []versionSource
+
+	// workspacePackage is the package selected in a npm, pnpm or yarn workspace
+	workspacePackage string
+}
+
+// versionSource is a version found in one of the version inputs
+type versionSource struct {
+	Name    string
+	Version string
 }
 
+const (
+	severityOff   = "off"
+	severityWarn  = "warn"
+	severityError = "error"
+)
+
+var (
+	// ErrVersionMismatch is returned when the version sources don't declare the same version
+	ErrVersionMismatch = errors.New("version mismatch")
+
+	errInvalidSeverity = errors.New("invalid severity")
+)
+
 var reBranchClean = regexp.MustCompile(`[^a-zA-Z0-9_\-]+`)
 
 const timeFormat = "2006-01-02-1504"
@@ -64,6 +106,10 @@ func (bi *BuildInfo) complete() error {
 		bi.GitSmartRef = bi.GitCommitHashShort
 	}
 
+	if bi.GitRepositoryURL != "" {
+		bi.GitRepositoryURL = normalizeRepositoryURL(bi.GitRepositoryURL)
+	}
+
 	if bi.BuildHost == "" {
 		if host, err := os.Hostname(); err != nil {
 			log.Warn("Could not get hostname", "err", err)
@@ -83,13 +129,13 @@ func (bi *BuildInfo) complete() error {
 	return nil
 }
 
-func (bi *BuildInfo) save(fileName string) error {
-	content, err := json.MarshalIndent(bi, "", "  ")
+func (bi *BuildInfo) save(writer outputWriter, fileName string, format buildInfoFormat) error {
+	content, err := bi.export(fileName, format)
 	if err != nil {
 		return fmt.Errorf("could not marshal build info: %w", err)
 	}
 
-	return os.WriteFile(fileName, content, 0644) //nolint:gosec
+	return writer.WriteFile(fileName, content, 0)
 }
 
 func (bi *BuildInfo) loadVersion(config *Config) error { //nolint:gocyclo
@@ -125,12 +171,36 @@ func (bi *BuildInfo) loadVersion(config *Config) error { //nolint:gocyclo
 		}
 	}
 
-	if fileVersion == "" {
+	bi.workspacePackage = config.Package
+
+	versionCheck := ConfigVersionCheck{}
+	if config.VersionCheck != nil {
+		versionCheck = *config.VersionCheck
+	}
+
+	// The package manager is a fallback of the version file, and is also fetched to be checked against the other
+	// sources
+	checkSources := versionCheck.Severity != "" && versionCheck.Severity != severityOff
+	if fileVersion == "" || checkSources {
 		if err = fetchPackageManagerInfo(config.Directory, bi); err != nil {
-			return fmt.Errorf("failed to fetch package manager info: %w", err)
+			if fileVersion == "" {
+				return fmt.Errorf("failed to fetch package manager info: %w", err)
+			}
+
+			log.Warn("Failed to fetch package manager info", "err", err)
 		}
 	}
 
+	bi.versionSources = nil
+	bi.addVersionSource("env", envVersion)
+	bi.addVersionSource("tag", tagVersion)
+	bi.addVersionSource("file", fileVersion)
+	bi.addVersionSource(bi.PackageManager, bi.VersionDeclared)
+
+	if err = bi.checkVersionSources(versionCheck.Severity); err != nil {
+		return err
+	}
+
 	switch {
 	case envVersion != "":
 		bi.VersionDeclared = envVersion
@@ -149,5 +219,129 @@ func (bi *BuildInfo) loadVersion(config *Config) error { //nolint:gocyclo
 		}
 	}
 
+	bi.loadNumericVersion()
+
 	return nil
 }
+
+func (bi *BuildInfo) addVersionSource(name, version string) {
+	if version != "" {
+		bi.versionSources = append(bi.versionSources, versionSource{Name: name, Version: version})
+	}
+}
+
+// checkVersionSources compares the normalized versions of all the sources, and depending on
+// the severity, logs or returns the mismatch
+func (bi *BuildInfo) checkVersionSources(severity string) error {
+	switch severity {
+	case "", severityOff:
+		return nil
+	case severityWarn, severityError:
+	default:
+		return fmt.Errorf("%w: %s", errInvalidSeverity, severity)
+	}
+
+	if len(bi.versionSources) < 2 {
+		return nil
+	}
+
+	reference := normalizeVersion(bi.versionSources[0].Version)
+	mismatch := false
+	descriptions := make([]string, 0, len(bi.versionSources))
+
+	for _, source := range bi.versionSources {
+		if normalizeVersion(source.Version) != reference {
+			mismatch = true
+		}
+
+		descriptions = append(descriptions, source.Name+"="+source.Version)
+	}
+
+	if !mismatch {
+		return nil
+	}
+
+	if severity == severityWarn {
+		log.Warn("The version sources don't match", "versions", strings.Join(descriptions, ", "))
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrVersionMismatch, strings.Join(descriptions, ", "))
+}
+
+// buildInfoField is a field of the build info, as exposed by the get command and the build info file
+type buildInfoField struct {
+	Name  string
+	Key   string
+	Value string
+	Saved bool
+}
+
+// matches tells if the field is designated by a name: its go name, its key or its snake case name
+func (f *buildInfoField) matches(name string) bool {
+	return strings.EqualFold(name, f.Name) || name == f.Key ||
+		joinWords(f.Name, "_", strings.ToLower) == strings.ToLower(name)
+}
+
+// fields returns the string fields of the build info. Their key is their JSON name, or their snake case name
+// if they aren't saved in the JSON build info file.
+func (bi *BuildInfo) fields() []buildInfoField {
+	value := reflect.ValueOf(bi).Elem()
+	fields := make([]buildInfoField, 0, value.NumField())
+
+	for i := 0; i < value.NumField(); i++ {
+		structField := value.Type().Field(i)
+		if !structField.IsExported() || structField.Type.Kind() != reflect.String {
+			continue
+		}
+
+		key := strings.Split(structField.Tag.Get("json"), ",")[0]
+		saved := key != "-"
+
+		if key == "" || key == "-" {
+			key = joinWords(structField.Name, "_", strings.ToLower)
+		}
+
+		fields = append(fields, buildInfoField{
+			Name:  structField.Name,
+			Key:   key,
+			Value: value.Field(i).String(),
+			Saved: saved,
+		})
+	}
+
+	for _, name := range sortedKeys(bi.Custom) {
+		fields = append(fields, buildInfoField{Name: "Custom." + name, Key: "custom." + name, Value: bi.Custom[name]})
+	}
+
+	return fields
+}
+
+// fieldValue returns the value of a field designated by its go name, its key or its snake case name
+func (bi *BuildInfo) fieldValue(name string) (string, error) {
+	if strings.HasPrefix(strings.ToLower(name), "env.") {
+		if value, ok := bi.Env[name[len("env."):]]; ok {
+			return value, nil
+		}
+	}
+
+	for _, field := range bi.fields() {
+		if field.matches(name) {
+			return field.Value, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", errUnknownField, name)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	errUnknownFormat  = errors.New("unknown build info format")
+	errUnknownKeyCase = errors.New("unknown key case")
+	errInvalidKey     = errors.New("invalid key")
+)
+
+var (
+	reEnvKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	reXMLKey = regexp.MustCompile(`^[\pL_][\pL\pN_.-]*$`)
+)
+
+const (
+	formatJSON       = "json"
+	formatYAML       = "yaml"
+	formatTOML       = "toml"
+	formatEnv        = "env"
+	formatProperties = "properties"
+	formatXML        = "xml"
+
+	keyCaseSnake     = "snake"
+	keyCaseCamel     = "camel"
+	keyCaseScreaming = "screaming"
+)
+
+// formatExtensions are the file extensions from which the format is deduced when it isn't specified
+var formatExtensions = map[string]string{
+	".json":       formatJSON,
+	".yaml":       formatYAML,
+	".yml":        formatYAML,
+	".toml":       formatTOML,
+	".env":        formatEnv,
+	".properties": formatProperties,
+	".xml":        formatXML,
+}
+
+// buildInfoFormat defines how the build info file is written
+type buildInfoFormat struct {
+	Format  string
+	Fields  []string
+	KeyCase string
+}
+
+func (f *buildInfoFormat) format(fileName string) string {
+	if f.Format != "" {
+		return strings.ToLower(f.Format)
+	}
+
+	if format, ok := formatExtensions[strings.ToLower(filepath.Ext(fileName))]; ok {
+		return format
+	}
+
+	return formatJSON
+}
+
+// buildInfoEntry is an entry of the build info file, the custom variables are grouped in a sub-entry
+type buildInfoEntry struct {
+	Key      string
+	Value    string
+	Children []buildInfoEntry
+}
+
+// export formats the build info
+func (bi *BuildInfo) export(fileName string, options buildInfoFormat) ([]byte, error) {
+	format := options.format(fileName)
+
+	// The default JSON output is the JSON serialization of the build info
+	if format == formatJSON && len(options.Fields) == 0 && (options.KeyCase == "" || options.KeyCase == keyCaseSnake) {
+		return json.MarshalIndent(bi, "", "  ")
+	}
+
+	keyCase := options.KeyCase
+	if keyCase == "" {
+		keyCase = keyCaseSnake
+
+		if format == formatEnv {
+			keyCase = keyCaseScreaming
+		}
+	}
+
+	entries, err := bi.entries(options.Fields, keyCase)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case formatJSON:
+		return exportJSON(entries), nil
+	case formatYAML:
+		return exportYAML(entries)
+	case formatTOML:
+		return exportTOML(entries), nil
+	case formatEnv:
+		if err := checkKeys(entries, reEnvKey, format); err != nil {
+			return nil, err
+		}
+
+		return exportFlat(entries, "_", func(key, value string) string {
+			return key + "=\"" + strings.ReplaceAll(jsonString(value), "$", `\$`) + "\"\n"
+		}), nil
+	case formatProperties:
+		return exportFlat(entries, ".", func(key, value string) string {
+			return propertiesEscape(key, true) + "=" + propertiesEscape(value, false) + "\n"
+		}), nil
+	case formatXML:
+		if err := checkKeys(entries, reXMLKey, format); err != nil {
+			return nil, err
+		}
+
+		return exportXML(entries, convertKeyCase("build_info", keyCase)), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnknownFormat, format)
+	}
+}
+
+// entries returns the selected fields, by default the ones of the JSON serialization and the custom variables
+func (bi *BuildInfo) entries(selection []string, keyCase string) ([]buildInfoEntry, error) {
+	switch keyCase {
+	case keyCaseSnake, keyCaseCamel, keyCaseScreaming:
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnknownKeyCase, keyCase)
+	}
+
+	var fields []buildInfoField
+
+	if len(selection) == 0 {
+		for _, field := range bi.fields() {
+			if field.Saved || strings.HasPrefix(field.Name, "Custom.") {
+				fields = append(fields, field)
+			}
+		}
+	} else {
+		for _, name := range selection {
+			selected, err := bi.selectFields(name)
+			if err != nil {
+				return nil, err
+			}
+
+			fields = append(fields, selected...)
+		}
+	}
+
+	var entries []buildInfoEntry
+
+	customIndex := -1
+
+	for _, field := range fields {
+		if field.Value == "" {
+			continue
+		}
+
+		// The custom variables keep the names of the config
+		if name := strings.TrimPrefix(field.Name, "Custom."); name != field.Name {
+			if customIndex < 0 {
+				customIndex = len(entries)
+				entries = append(entries, buildInfoEntry{Key: convertKeyCase("custom", keyCase)})
+			}
+
+			entries[customIndex].Children = append(entries[customIndex].Children, buildInfoEntry{
+				Key:   name,
+				Value: field.Value,
+			})
+
+			continue
+		}
+
+		entries = append(entries, buildInfoEntry{Key: convertKeyCase(field.Key, keyCase), Value: field.Value})
+	}
+
+	return entries, nil
+}
+
+// selectFields returns the fields designated by a name, "custom" designating all the custom variables
+// and "*" all the fields
+func (bi *BuildInfo) selectFields(name string) ([]buildInfoField, error) {
+	var selected []buildInfoField
+
+	for _, field := range bi.fields() {
+		isCustom := strings.HasPrefix(field.Name, "Custom.")
+
+		if name == "*" || (strings.EqualFold(name, "custom") && isCustom) || field.matches(name) {
+			selected = append(selected, field)
+		}
+	}
+
+	if len(selected) == 0 && !strings.EqualFold(name, "custom") {
+		return nil, fmt.Errorf("%w: %s", errUnknownField, name)
+	}
+
+	return selected, nil
+}
+
+func convertKeyCase(key, keyCase string) string {
+	switch keyCase {
+	case keyCaseCamel:
+		return camelCase(key)
+	case keyCaseScreaming:
+		return joinWords(key, "_", strings.ToUpper)
+	default:
+		return joinWords(key, "_", strings.ToLower)
+	}
+}
+
+// checkKeys fails if a key can't be written in the format, which can happen with the names of the custom variables
+func checkKeys(entries []buildInfoEntry, re *regexp.Regexp, format string) error {
+	for _, entry := range entries {
+		if !re.MatchString(entry.Key) {
+			return fmt.Errorf("%w for the %s format: %q", errInvalidKey, format, entry.Key)
+		}
+
+		if err := checkKeys(entry.Children, re, format); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func exportJSON(entries []buildInfoEntry) []byte {
+	var b bytes.Buffer
+
+	writeJSONEntries(&b, entries, "")
+	b.WriteString("\n")
+
+	return b.Bytes()
+}
+
+func writeJSONEntries(b *bytes.Buffer, entries []buildInfoEntry, indent string) {
+	b.WriteString("{")
+
+	for i, entry := range entries {
+		if i > 0 {
+			b.WriteString(",")
+		}
+
+		b.WriteString("\n" + indent + "  \"" + jsonString(entry.Key) + "\": ")
+
+		if entry.Children != nil {
+			writeJSONEntries(b, entry.Children, indent+"  ")
+		} else {
+			b.WriteString("\"" + jsonString(entry.Value) + "\"")
+		}
+	}
+
+	b.WriteString("\n" + indent + "}")
+}
+
+func exportYAML(entries []buildInfoEntry) ([]byte, error) {
+	return yaml.Marshal(yamlEntries(entries))
+}
+
+func yamlEntries(entries []buildInfoEntry) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+
+	for _, entry := range entries {
+		value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: entry.Value}
+		if entry.Children != nil {
+			value = yamlEntries(entry.Children)
+		}
+
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: entry.Key}, value)
+	}
+
+	return node
+}
+
+func exportTOML(entries []buildInfoEntry) []byte {
+	var b bytes.Buffer
+
+	var tables []buildInfoEntry
+
+	for _, entry := range entries {
+		if entry.Children != nil {
+			tables = append(tables, entry)
+
+			continue
+		}
+
+		b.WriteString(tomlKey(entry.Key) + " = \"" + jsonString(entry.Value) + "\"\n")
+	}
+
+	// The tables must come after the keys of the root table
+	for _, table := range tables {
+		b.WriteString("\n[" + tomlKey(table.Key) + "]\n")
+
+		for _, entry := range table.Children {
+			b.WriteString(tomlKey(entry.Key) + " = \"" + jsonString(entry.Value) + "\"\n")
+		}
+	}
+
+	return b.Bytes()
+}
+
+func tomlKey(key string) string {
+	for _, r := range key {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' || r > unicode.MaxASCII {
+			return "\"" + jsonString(key) + "\""
+		}
+	}
+
+	return key
+}
+
+// exportFlat writes one line per entry, the keys of the custom variables being prefixed by "custom"
+func exportFlat(entries []buildInfoEntry, separator string, line func(key, value string) string) []byte {
+	var b bytes.Buffer
+
+	for _, entry := range entries {
+		if entry.Children == nil {
+			b.WriteString(line(entry.Key, entry.Value))
+
+			continue
+		}
+
+		for _, child := range entry.Children {
+			b.WriteString(line(entry.Key+separator+child.Key, child.Value))
+		}
+	}
+
+	return b.Bytes()
+}
+
+// propertiesEscape escapes a key or a value of a java properties file
+func propertiesEscape(s string, key bool) string {
+	var b strings.Builder
+
+	for i, r := range s {
+		switch {
+		case r == '\\' || r == '=' || r == ':' || r == '#' || r == '!' || (r == ' ' && (key || i == 0)):
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r > 0x7e:
+			// The characters outside of the basic plane are written as surrogate pairs
+			for _, u := range utf16.Encode([]rune{r}) {
+				fmt.Fprintf(&b, `\u%04x`, u)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+func exportXML(entries []buildInfoEntry, root string) []byte {
+	var b bytes.Buffer
+
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	writeXMLEntries(&b, []buildInfoEntry{{Key: root, Children: entries}}, "")
+
+	return b.Bytes()
+}
+
+func writeXMLEntries(b *bytes.Buffer, entries []buildInfoEntry, indent string) {
+	for _, entry := range entries {
+		if entry.Children == nil {
+			b.WriteString(indent + "<" + entry.Key + ">" + xmlEscape(entry.Value) + "</" + entry.Key + ">\n")
+
+			continue
+		}
+
+		b.WriteString(indent + "<" + entry.Key + ">\n")
+		writeXMLEntries(b, entry.Children, indent+"  ")
+		b.WriteString(indent + "</" + entry.Key + ">\n")
+	}
+}
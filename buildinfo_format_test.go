@@ -0,0 +1,158 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func getFormatBuildInfo() *BuildInfo {
+	return &BuildInfo{
+		CIInfoVersion:      "0.4.0",
+		Version:            "1.2.3-main-f96a756",
+		VersionDeclared:    "1.2.3",
+		GitCommitHashShort: "f96a756",
+		GitBranch:          "main",
+		Custom:             map[string]string{"vendor": "Acme \"Rockets\"", "productName": "Rocket"},
+	}
+}
+
+func TestBuildInfoExport(t *testing.T) {
+	fields := []string{"version", "GitCommitHashShort", "version_declared", "custom"}
+
+	tests := []struct {
+		fileName string
+		options  buildInfoFormat
+		expected string
+	}{
+		{
+			fileName: "build.json",
+			options:  buildInfoFormat{},
+			expected: `{
+  "ci_info_version": "0.4.0",
+  "version": "1.2.3-main-f96a756",
+  "git_branch": "main",
+  "custom": {
+    "productName": "Rocket",
+    "vendor": "Acme \"Rockets\""
+  }
+}`,
+		},
+		{
+			fileName: "build.json",
+			options:  buildInfoFormat{Fields: fields, KeyCase: keyCaseCamel},
+			expected: `{
+  "version": "1.2.3-main-f96a756",
+  "gitCommitHashShort": "f96a756",
+  "versionDeclared": "1.2.3",
+  "custom": {
+    "productName": "Rocket",
+    "vendor": "Acme \"Rockets\""
+  }
+}
+`,
+		},
+		{
+			fileName: "build.yml",
+			options:  buildInfoFormat{Fields: fields},
+			expected: `version: 1.2.3-main-f96a756
+git_commit_hash_short: f96a756
+version_declared: 1.2.3
+custom:
+    productName: Rocket
+    vendor: Acme "Rockets"
+`,
+		},
+		{
+			fileName: "build.toml",
+			options:  buildInfoFormat{Fields: fields},
+			expected: `version = "1.2.3-main-f96a756"
+git_commit_hash_short = "f96a756"
+version_declared = "1.2.3"
+
+[custom]
+productName = "Rocket"
+vendor = "Acme \"Rockets\""
+`,
+		},
+		{
+			fileName: "build.env",
+			options:  buildInfoFormat{Fields: fields},
+			expected: `VERSION="1.2.3-main-f96a756"
+GIT_COMMIT_HASH_SHORT="f96a756"
+VERSION_DECLARED="1.2.3"
+CUSTOM_productName="Rocket"
+CUSTOM_vendor="Acme \"Rockets\""
+`,
+		},
+		{
+			fileName: "build.properties",
+			options:  buildInfoFormat{Fields: fields, KeyCase: keyCaseCamel},
+			expected: `version=1.2.3-main-f96a756
+gitCommitHashShort=f96a756
+versionDeclared=1.2.3
+custom.productName=Rocket
+custom.vendor=Acme "Rockets"
+`,
+		},
+		{
+			fileName: "build.out",
+			options:  buildInfoFormat{Format: "xml", Fields: []string{"version", "custom"}},
+			expected: `<?xml version="1.0" encoding="UTF-8"?>
+<build_info>
+  <version>1.2.3-main-f96a756</version>
+  <custom>
+    <productName>Rocket</productName>
+    <vendor>Acme &#34;Rockets&#34;</vendor>
+  </custom>
+</build_info>
+`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.fileName+"/"+test.options.Format+test.options.KeyCase, func(t *testing.T) {
+			content, err := getFormatBuildInfo().export(test.fileName, test.options)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, string(content))
+		})
+	}
+}
+
+func TestBuildInfoExportErrors(t *testing.T) {
+	a := require.New(t)
+	bi := getFormatBuildInfo()
+
+	_, err := bi.export("build.json", buildInfoFormat{Format: "ini"})
+	a.ErrorIs(err, errUnknownFormat)
+
+	_, err = bi.export("build.json", buildInfoFormat{KeyCase: "kebab"})
+	a.ErrorIs(err, errUnknownKeyCase)
+
+	_, err = bi.export("build.json", buildInfoFormat{Fields: []string{"nope"}})
+	a.ErrorIs(err, errUnknownField)
+
+	// The custom variables names can't always be written as XML elements or environment variables
+	for _, name := range []string{"2fa", "product name", "<tag>"} {
+		bi.Custom = map[string]string{name: "value"}
+
+		_, err = bi.export("build.xml", buildInfoFormat{})
+		a.ErrorIs(err, errInvalidKey, name)
+
+		_, err = bi.export("build.env", buildInfoFormat{})
+		a.ErrorIs(err, errInvalidKey, name)
+
+		_, err = bi.export("build.json", buildInfoFormat{})
+		a.NoError(err, name)
+	}
+}
+
+func TestPropertiesEscape(t *testing.T) {
+	a := require.New(t)
+
+	a.Equal(`a\=b\:c`, propertiesEscape("a=b:c", false))
+	a.Equal(`\ lead trail `, propertiesEscape(" lead trail ", false))
+	a.Equal(`my\ key`, propertiesEscape("my key", true))
+	a.Equal(`caf\u00e9 \ud83d\ude80\n`, propertiesEscape("café 🚀\n", false))
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+	"path"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -60,8 +62,8 @@ func TestSave(t *testing.T) {
 		GitBranch:       "main",
 	}
 	a.Nil(bi.complete())
-	a.Nil(bi.save("/tmp/buildinfo.json"))
-	a.NotNil(bi.save("/not-existing-path/buildinfo.json"))
+	a.Nil(bi.save(diskOutput{}, "/tmp/buildinfo.json", buildInfoFormat{}))
+	a.NotNil(bi.save(diskOutput{}, "/proc/not-existing-path/buildinfo.json", buildInfoFormat{}))
 }
 
 func TestReadme(t *testing.T) {
@@ -95,3 +97,71 @@ func TestEnvVersion(t *testing.T) {
 	a.NoError(bi.loadVersion(config))
 	a.Equal("1.2.3", bi.Version)
 }
+
+func TestVersionSourcesCheck(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(dir, "package.json"), []byte(`{"version": "2.2.9"}`), 0600))
+
+	newConfig := func(severity string) *Config {
+		config := createDefaultConfig()
+		config.Directory = dir
+		config.VersionCheck.Severity = severity
+
+		return config
+	}
+
+	t.Run("mismatch error", func(t *testing.T) {
+		a := require.New(t)
+		bi := &BuildInfo{GitTag: "v2.3.0"}
+		a.ErrorIs(bi.loadVersion(newConfig(severityError)), ErrVersionMismatch)
+	})
+
+	t.Run("mismatch warn", func(t *testing.T) {
+		a := require.New(t)
+		bi := &BuildInfo{GitTag: "v2.3.0"}
+		a.NoError(bi.loadVersion(newConfig(severityWarn)))
+		a.Equal("2.3.0", bi.Version)
+		a.Equal([]versionSource{{"tag", "2.3.0"}, {"npm", "2.2.9"}}, bi.versionSources)
+	})
+
+	t.Run("match", func(t *testing.T) {
+		a := require.New(t)
+		bi := &BuildInfo{GitTag: "v2.2.9"}
+		a.NoError(bi.loadVersion(newConfig(severityError)))
+	})
+
+	t.Run("invalid severity", func(t *testing.T) {
+		a := require.New(t)
+		bi := &BuildInfo{GitTag: "v2.2.9"}
+		a.ErrorIs(bi.loadVersion(newConfig("fatal")), errInvalidSeverity)
+	})
+
+	// The package manager is only a fallback of the version file, unless the sources are checked
+	t.Run("version file", func(t *testing.T) {
+		a := require.New(t)
+		versionFile := path.Join(t.TempDir(), "VERSION")
+		a.NoError(os.WriteFile(versionFile, []byte("2.2.9\n"), 0600))
+
+		config := newConfig(severityOff)
+		config.InputVersionFile = ConfigVersionInputFile{File: versionFile, Pattern: "([0-9.]+)"}
+
+		bi := &BuildInfo{}
+		a.NoError(bi.loadVersion(config))
+		a.Equal("", bi.PackageManager)
+		a.Equal("2.2.9", bi.VersionDeclared)
+
+		config.VersionCheck.Severity = severityWarn
+		bi = &BuildInfo{}
+		a.NoError(bi.loadVersion(config))
+		a.Equal("npm", bi.PackageManager)
+
+		// A broken manifest doesn't matter when the version comes from a file
+		config.Directory = t.TempDir()
+		a.NoError(os.WriteFile(path.Join(config.Directory, "package.json"), []byte(`{"version": `), 0600))
+
+		for _, severity := range []string{severityOff, severityWarn} {
+			config.VersionCheck.Severity = severity
+			a.NoError((&BuildInfo{}).loadVersion(config), severity)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//go:embed templates/*.tpl
+var builtinTemplatesFS embed.FS
+
+// windowsOptions are the options of the Windows resources templates
+const windowsOptions = "company, product, description, copyright, internal_name, original_filename"
+
+var errUnknownBuiltin = errors.New("unknown builtin template")
+
+// builtinTemplate is a template shipped with ci-info
+type builtinTemplate struct {
+	Name        string
+	OutputFile  string
+	Language    string
+	Description string
+}
+
+// builtinTemplates are the templates that can be referenced with the "builtin" property of a template
+var builtinTemplates = []*builtinTemplate{
+	{"go", "build.go", "go", "Go variables (options: package, prefix)"},
+	{"c", "build_info.h", "c", "C/C++ header (options: prefix, guard)"},
+	{"python", "build_info.py", "python", "Python module (options: prefix)"},
+	{"typescript", "buildInfo.ts", "typescript", "TypeScript module (options: prefix)"},
+	{"javascript", "buildInfo.mjs", "javascript", "JavaScript ES module (options: prefix)"},
+	{"commonjs", "buildInfo.cjs", "javascript", "JavaScript CommonJS module (options: prefix)"},
+	{"java", "BuildInfo.java", "java", "Java class (options: package, class, prefix)"},
+	{"kotlin", "BuildInfo.kt", "kotlin", "Kotlin object (options: package, class, prefix)"},
+	{"rust", "build_info.rs", "rust", "Rust constants (options: prefix)"},
+	{"csharp", "BuildInfo.cs", "csharp", "C# class (options: namespace, class, prefix)"},
+	{"swift", "BuildInfo.swift", "swift", "Swift enum (options: class, prefix)"},
+	{"shell", "build_info.sh", "shell", "Shell variables (options: prefix)"},
+	{"dotenv", "build.env", "", "Dotenv file (options: prefix)"},
+	{"makefile", "build_info.mk", "", "Makefile variables (options: prefix)"},
+	{"windows-rc", "versioninfo.rc", "rc", "Windows VERSIONINFO resource (options: " + windowsOptions + ")"},
+	{"goversioninfo", "versioninfo.json", "json", "goversioninfo resource (options: " + windowsOptions + ")"},
+}
+
+func getBuiltinTemplate(name string) (*builtinTemplate, error) {
+	for _, builtin := range builtinTemplates {
+		if builtin.Name == name {
+			return builtin, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s (available: %s)", errUnknownBuiltin, name, strings.Join(builtinTemplateNames(), ", "))
+}
+
+func (b *builtinTemplate) content() (string, error) {
+	content, err := builtinTemplatesFS.ReadFile("templates/" + b.Name + ".tpl")
+
+	return string(content), err
+}
+
+func builtinTemplateNames() []string {
+	names := make([]string, 0, len(builtinTemplates))
+
+	for _, builtin := range builtinTemplates {
+		names = append(names, builtin.Name)
+	}
+
+	return names
+}
+
+// printBuiltinTemplates lists the builtin templates, so that they can be picked when creating the config
+func printBuiltinTemplates() {
+	fmt.Println("Available builtin templates (-t name1,name2):")
+
+	for _, builtin := range builtinTemplates {
+		fmt.Printf("  %-14s %-16s %s\n", builtin.Name, builtin.OutputFile, builtin.Description)
+	}
+}
+
+// getBuiltinConfigTemplates returns the template configs for a comma separated list of builtin templates
+func getBuiltinConfigTemplates(names string) ([]*ConfigTemplate, error) {
+	var templates []*ConfigTemplate
+
+	for _, name := range strings.Split(names, ",") {
+		builtin, err := getBuiltinTemplate(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+
+		templates = append(templates, &ConfigTemplate{
+			Builtin:    builtin.Name,
+			OutputFile: builtin.OutputFile,
+		})
+	}
+
+	return templates, nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func getTrickyBuildInfo() *BuildInfo {
+	return &BuildInfo{
+		Version:            "1.2.3-fix-quotes-a6850c9",
+		VersionCore:        "1.2.3",
+		VersionBuild:       "45",
+		VersionNumeric:     "1.2.3.45",
+		GitCommitHash:      "a6850c90c8d3c81377cee5701f79dfbbd6e5a756",
+		GitCommitHashShort: "a6850c9",
+		GitBranch:          `fix/"quotes"\$HOME#`,
+		GitSmartRef:        "fix-quotes-a6850c9",
+		BuildDate:          "2022-09-18T10:49:46Z",
+	}
+}
+
+func TestBuiltinTemplates(t *testing.T) {
+	for _, builtin := range builtinTemplates {
+		builtin := builtin
+		t.Run(builtin.Name, func(t *testing.T) {
+			a := require.New(t)
+			tpl := &ConfigTemplate{Builtin: builtin.Name}
+
+			content, err := tpl.load("")
+			a.NoError(err)
+
+			output, err := tpl.render(&Config{}, content, getTrickyBuildInfo())
+			a.NoError(err)
+			a.Contains(output, "1.2.3-fix-quotes-a6850c9")
+			a.Contains(output, "ci-info")
+			a.Equal(builtin.OutputFile, tpl.outputFileName())
+		})
+	}
+}
+
+func TestBuiltinTemplateGo(t *testing.T) {
+	a := require.New(t)
+	tpl := &ConfigTemplate{Builtin: "go", Options: map[string]string{"package": "version", "prefix": "App"}}
+
+	content, err := tpl.load("")
+	a.NoError(err)
+
+	output, err := tpl.render(&Config{}, content, getTrickyBuildInfo())
+	a.NoError(err)
+
+	file, err := parser.ParseFile(token.NewFileSet(), "build.go", output, parser.ParseComments)
+	a.NoError(err, output)
+	a.Equal("version", file.Name.Name)
+	a.Contains(output, `AppBranch = "fix/\"quotes\"\\$HOME#"`)
+}
+
+func TestBuiltinTemplateEscaping(t *testing.T) {
+	for builtin, expected := range map[string]string{
+		"c":        `#define BUILD_BRANCH "fix/\"quotes\"\\$HOME#"`,
+		"python":   `BRANCH = "fix/\"quotes\"\\$HOME#"`,
+		"kotlin":   `const val BRANCH = "fix/\"quotes\"\\\$HOME#"`,
+		"shell":    `BUILD_BRANCH='fix/"quotes"\$HOME#'`,
+		"dotenv":   `BUILD_BRANCH="fix/\"quotes\"\\\$HOME#"`,
+		"makefile": `BUILD_BRANCH := fix/"quotes"\$$HOME\#`,
+	} {
+		t.Run(builtin, func(t *testing.T) {
+			a := require.New(t)
+			tpl := &ConfigTemplate{Builtin: builtin}
+
+			content, err := tpl.load("")
+			a.NoError(err)
+
+			output, err := tpl.render(&Config{}, content, getTrickyBuildInfo())
+			a.NoError(err)
+			a.Contains(output, expected)
+		})
+	}
+}
+
+// TestBuiltinTemplatePrefix checks that the identifiers aren't escaped like strings: "$" is escaped in the
+// JavaScript strings but valid in the identifiers
+func TestBuiltinTemplatePrefix(t *testing.T) {
+	for builtin, expected := range map[string]string{
+		"javascript": `export const $app_version = "1.2.3";`,
+		"typescript": `export const $app_version: string = "1.2.3";`,
+		"commonjs":   `  $app_version: "1.2.3",`,
+		"java":       `public static final String $app_VERSION = "1.2.3";`,
+	} {
+		t.Run(builtin, func(t *testing.T) {
+			a := require.New(t)
+			tpl := &ConfigTemplate{Builtin: builtin, Options: map[string]string{"prefix": "$app_"}}
+
+			content, err := tpl.load("")
+			a.NoError(err)
+
+			output, err := tpl.render(&Config{}, content, &BuildInfo{Version: "1.2.3"})
+			a.NoError(err)
+			a.Contains(output, expected)
+		})
+	}
+}
+
+func TestBuiltinTemplateUnknown(t *testing.T) {
+	_, err := (&ConfigTemplate{Builtin: "cobol"}).load("")
+	require.ErrorIs(t, err, errUnknownBuiltin)
+}
+
+func TestMainInitBuiltin(t *testing.T) {
+	a := require.New(t)
+	dir := t.TempDir()
+	configFile := path.Join(dir, ".ci-info.json")
+
+	a.NoError(runMain([]string{"-i", "-c", configFile, "-t", "go,python"}))
+
+	config, err := loadConfig(configFile)
+	a.NoError(err)
+	a.Len(config.Templates, 2)
+	a.Equal("python", config.Templates[1].Builtin)
+
+	a.NoError(runMain([]string{"-c", configFile}))
+	a.FileExists(path.Join(dir, "build.go"))
+
+	content, err := os.ReadFile(path.Join(dir, "build_info.py"))
+	a.NoError(err)
+	a.Contains(string(content), "VERSION = ")
+
+	a.ErrorIs(runMain([]string{"-i", "-c", configFile, "-t", "cobol"}), errUnknownBuiltin)
+}
+
+func TestBuiltinTemplateWindows(t *testing.T) {
+	a := require.New(t)
+	options := map[string]string{"company": `Acme "Corp"`, "product": "App", "copyright": "© 2024 Acme"}
+
+	tpl := &ConfigTemplate{Builtin: "windows-rc", Options: options}
+	content, err := tpl.load("")
+	a.NoError(err)
+
+	output, err := tpl.render(&Config{}, content, getTrickyBuildInfo())
+	a.NoError(err)
+	a.Contains(output, " FILEVERSION 1,2,3,45\n")
+	a.Contains(output, " FILEFLAGS VS_FF_PRERELEASE\n")
+	a.Contains(output, `VALUE "CompanyName", "Acme ""Corp"""`)
+	a.Contains(output, `VALUE "FileDescription", "App"`)
+	a.Contains(output, `VALUE "LegalCopyright", "© 2024 Acme"`)
+
+	tpl = &ConfigTemplate{Builtin: "goversioninfo", Options: options}
+	content, err = tpl.load("")
+	a.NoError(err)
+
+	output, err = tpl.render(&Config{}, content, getTrickyBuildInfo())
+	a.NoError(err)
+
+	var versionInfo struct {
+		FixedFileInfo struct {
+			FileVersion struct{ Major, Minor, Patch, Build int }
+			FileFlags   string
+		}
+		StringFileInfo map[string]string
+	}
+	a.NoError(json.Unmarshal([]byte(output), &versionInfo), output)
+	a.Equal(struct{ Major, Minor, Patch, Build int }{1, 2, 3, 45}, versionInfo.FixedFileInfo.FileVersion)
+	a.Equal("02", versionInfo.FixedFileInfo.FileFlags)
+	a.Equal(`Acme "Corp"`, versionInfo.StringFileInfo["CompanyName"])
+	a.Equal("1.2.3-fix-quotes-a6850c9", versionInfo.StringFileInfo["ProductVersion"])
+}
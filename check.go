@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+var (
+	// ErrOutputDrift is returned when the output files on disk aren't the ones that would be generated
+	ErrOutputDrift = errors.New("output files are out of date")
+
+	errUnknownField = errors.New("unknown build info field")
+)
+
+// checkOutputFiles generates the output files in memory and prints the diff with the ones on disk.
+// The lines that depend on the ignored fields aren't compared.
+func checkOutputFiles(config *Config, buildInfo *BuildInfo) error {
+	expected, err := generateOutputFiles(config, buildInfo)
+	if err != nil {
+		return err
+	}
+
+	// The output files are generated a second time with other values for the ignored fields, the lines
+	// that change between the two generations are the volatile ones.
+	alternative := expected
+
+	if config.Check != nil && len(config.Check.Ignore) > 0 {
+		altBuildInfo, err := buildInfo.withAlteredFields(config)
+		if err != nil {
+			return err
+		}
+
+		if alternative, err = generateOutputFiles(config, altBuildInfo); err != nil {
+			return err
+		}
+	}
+
+	var staleFiles []string
+
+	for _, fileName := range expected.fileNames {
+		if fileName == stdoutPath {
+			continue
+		}
+
+		actual, err := os.ReadFile(fileName) //nolint:gosec
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not read %s: %w", fileName, err)
+		}
+
+		content := expected.files[fileName]
+		if outputMatches(string(actual), string(content), string(alternative.files[fileName])) {
+			log.Debug("File is up to date", "path", fileName)
+
+			continue
+		}
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(actual)),
+			B:        difflib.SplitLines(string(content)),
+			FromFile: fileName,
+			ToFile:   fileName + " (expected)",
+			Context:  3,
+		})
+		if err != nil {
+			return fmt.Errorf("could not diff %s: %w", fileName, err)
+		}
+
+		fmt.Print(diff)
+
+		staleFiles = append(staleFiles, fileName)
+	}
+
+	if len(staleFiles) > 0 {
+		return fmt.Errorf("%w: %s", ErrOutputDrift, strings.Join(staleFiles, ", "))
+	}
+
+	return nil
+}
+
+// generateOutputFiles generates the output files in memory
+func generateOutputFiles(config *Config, buildInfo *BuildInfo) (*memoryOutput, error) {
+	memConfig := *config
+	output := newMemoryOutput()
+	memConfig.output = output
+	memConfig.CIExport = nil
+	memConfig.Signing = nil
+
+	if err := saveOutputFiles(&memConfig, buildInfo); err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// outputMatches compares the actual content with the expected one, the lines that are different in the
+// alternative content are ignored
+func outputMatches(actual, expected, alternative string) bool {
+	if actual == expected {
+		return true
+	}
+
+	actualLines := strings.Split(actual, "\n")
+	expectedLines := strings.Split(expected, "\n")
+	alternativeLines := strings.Split(alternative, "\n")
+
+	if len(actualLines) != len(expectedLines) || len(expectedLines) != len(alternativeLines) {
+		return false
+	}
+
+	for i := range actualLines {
+		if actualLines[i] != expectedLines[i] && expectedLines[i] == alternativeLines[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// withAlteredFields returns a copy of the build info with different values for the ignored fields
+func (bi *BuildInfo) withAlteredFields(config *Config) (*BuildInfo, error) {
+	altered := *bi
+	value := reflect.ValueOf(&altered).Elem()
+
+	// The fields are designated like in the get command: BuildDate, build_date...
+	for _, name := range config.Check.Ignore {
+		var field reflect.Value
+
+		for _, f := range bi.fields() {
+			if f.matches(name) {
+				field = value.FieldByName(f.Name)
+
+				break
+			}
+		}
+
+		if !field.IsValid() || field.Kind() != reflect.String || !field.CanSet() {
+			return nil, fmt.Errorf("%w: %s", errUnknownField, name)
+		}
+
+		field.SetString(alterValue(field.String()))
+	}
+
+	// The variables can depend on the ignored fields
+	if err := altered.loadVariables(config); err != nil {
+		return nil, err
+	}
+
+	return &altered, nil
+}
+
+// alterValue returns a different value of the same kind, so that it can still be used by the template functions
+func alterValue(value string) string {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Add(25*time.Hour + 61*time.Minute).Format(layout)
+		}
+	}
+
+	if value == "" {
+		return "ignored"
+	}
+
+	return value + "-ignored"
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputMatches(t *testing.T) {
+	a := require.New(t)
+
+	a.True(outputMatches("a\nb\n", "a\nb\n", "a\nb\n"))
+	a.False(outputMatches("a\nb\n", "a\nc\n", "a\nc\n"))
+
+	// The lines that differ in the alternative are volatile
+	a.True(outputMatches("a\ndate=1\n", "a\ndate=2\n", "a\ndate=3\n"))
+	a.False(outputMatches("b\ndate=1\n", "a\ndate=2\n", "a\ndate=3\n"))
+	a.False(outputMatches("a\ndate=1\nextra\n", "a\ndate=2\n", "a\ndate=3\n"))
+}
+
+func TestAlterValue(t *testing.T) {
+	a := require.New(t)
+
+	a.Equal("2022-04-25T02:01:00Z", alterValue("2022-04-24T00:00:00Z"))
+	a.Equal("main-ignored", alterValue("main"))
+	a.Equal("ignored", alterValue(""))
+}
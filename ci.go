@@ -5,15 +5,20 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 const sTrue = "true"
 const refTags = "refs/tags/"
+const refHeads = "refs/heads/"
 
 var errCouldNotFindVersion = errors.New("could not find version")
 
@@ -24,6 +29,8 @@ var ciSolutionsFetchers = []CIInfoFetcher{
 	&droneCIInfoFetcher{},
 	&travisCIInfoFetcher{},
 	&jenkinsCIInfoFetcher{},
+	&azurePipelinesCIInfoFetcher{},
+	&buildkiteCIInfoFetcher{},
 }
 
 var packageManagerFetchers = []CIInfoFetcher{
@@ -31,6 +38,12 @@ var packageManagerFetchers = []CIInfoFetcher{
 	&gradleInfoFetcher{},
 	&mavenInfoFetcher{},
 	&nugetInfoFetcher{},
+	&composerInfoFetcher{},
+	&rubyGemsInfoFetcher{},
+	&dartInfoFetcher{},
+	&elixirInfoFetcher{},
+	&cmakeInfoFetcher{},
+	&helmInfoFetcher{},
 }
 
 func fetchCISolutionInfo(dir string, bi *BuildInfo) error {
@@ -53,7 +66,10 @@ func fetchCIInfo(dir string, bi *BuildInfo, fetchers []CIInfoFetcher, target *st
 			return fmt.Errorf("failed to fetch CI info: %w", err)
 		}
 
-		*target = fetcher.String()
+		// Some fetchers can be more specific about what they detected
+		if *target == "" {
+			*target = fetcher.String()
+		}
 
 		break
 	}
@@ -83,6 +99,8 @@ func (c circleCIInfoFetcher) Fetch(_ string, bi *BuildInfo) error {
 	bi.GitTag = os.Getenv("CIRCLE_TAG")
 	bi.GitBranch = os.Getenv("CIRCLE_BRANCH")
 	bi.CIBuildNumber = os.Getenv("CIRCLE_BUILD_NUM")
+	bi.GitRepositoryURL = os.Getenv("CIRCLE_REPOSITORY_URL")
+	bi.CIBuildURL = os.Getenv("CIRCLE_BUILD_URL")
 
 	return nil
 }
@@ -97,7 +115,7 @@ type githubActionsCIInfoFetcher struct{}
 
 // Detect if it's a suited fetcher
 func (f githubActionsCIInfoFetcher) Detect(_ string) bool {
-	return os.Getenv("GITHUB_ACTION") == sTrue
+	return os.Getenv("GITHUB_ACTIONS") == sTrue
 }
 
 // Fetch fetches the CI information
@@ -106,9 +124,17 @@ func (f githubActionsCIInfoFetcher) Fetch(_ string, bi *BuildInfo) error {
 	bi.CIBuildNumber = os.Getenv("GITHUB_RUN_ID")
 	bi.GitBranch = os.Getenv("GITHUB_HEAD_REF")
 
+	if repository := os.Getenv("GITHUB_REPOSITORY"); repository != "" {
+		bi.GitRepositoryURL = strings.TrimSuffix(os.Getenv("GITHUB_SERVER_URL"), "/") + "/" + repository
+		bi.CIBuildURL = bi.GitRepositoryURL + "/actions/runs/" + bi.CIBuildNumber
+	}
+
+	// GITHUB_HEAD_REF is only set for pull requests
 	ref := os.Getenv("GITHUB_REF")
 	if strings.HasPrefix(ref, refTags) {
 		bi.GitTag = ref[len(refTags):]
+	} else if bi.GitBranch == "" && strings.HasPrefix(ref, refHeads) {
+		bi.GitBranch = ref[len(refHeads):]
 	}
 
 	return nil
@@ -133,6 +159,7 @@ func (t travisCIInfoFetcher) Fetch(_ string, bi *BuildInfo) error {
 	bi.GitTag = os.Getenv("TRAVIS_TAG")
 	bi.GitBranch = os.Getenv("TRAVIS_BRANCH")
 	bi.CIBuildNumber = os.Getenv("TRAVIS_BUILD_NUMBER")
+	bi.CIBuildURL = os.Getenv("TRAVIS_BUILD_WEB_URL")
 
 	return nil
 }
@@ -155,6 +182,8 @@ func (f gitLabInfoFetcher) Fetch(_ string, bi *BuildInfo) error {
 	bi.GitTag = os.Getenv("CI_COMMIT_TAG")
 	bi.GitBranch = os.Getenv("CI_COMMIT_REF_NAME")
 	bi.CIBuildNumber = os.Getenv("CI_PIPELINE_ID")
+	bi.GitRepositoryURL = os.Getenv("CI_PROJECT_URL")
+	bi.CIBuildURL = os.Getenv("CI_PIPELINE_URL")
 
 	return nil
 }
@@ -178,6 +207,8 @@ func (f droneCIInfoFetcher) Fetch(_ string, bi *BuildInfo) error {
 	bi.GitTag = os.Getenv("DRONE_TAG")
 	bi.GitBranch = os.Getenv("DRONE_BRANCH")
 	bi.CIBuildNumber = os.Getenv("DRONE_BUILD_NUMBER")
+	bi.GitRepositoryURL = os.Getenv("DRONE_REPO_LINK")
+	bi.CIBuildURL = os.Getenv("DRONE_BUILD_LINK")
 
 	return nil
 }
@@ -201,6 +232,8 @@ func (f jenkinsCIInfoFetcher) Fetch(_ string, bi *BuildInfo) error {
 	bi.GitTag = os.Getenv("GIT_TAG")
 	bi.GitBranch = os.Getenv("GIT_BRANCH")
 	bi.CIBuildNumber = os.Getenv("BUILD_NUMBER")
+	bi.GitRepositoryURL = os.Getenv("GIT_URL")
+	bi.CIBuildURL = os.Getenv("BUILD_URL")
 
 	return nil
 }
@@ -209,6 +242,126 @@ func (f jenkinsCIInfoFetcher) String() string {
 	return "jenkins"
 }
 
+// azurePipelinesCIInfoFetcher is a fetcher for Azure Pipelines
+// see https://learn.microsoft.com/en-us/azure/devops/pipelines/build/variables
+type azurePipelinesCIInfoFetcher struct{}
+
+// Detect if it's a suited fetcher
+func (f azurePipelinesCIInfoFetcher) Detect(_ string) bool {
+	return strings.EqualFold(os.Getenv("TF_BUILD"), sTrue)
+}
+
+// Fetch fetches the CI information
+func (f azurePipelinesCIInfoFetcher) Fetch(_ string, bi *BuildInfo) error {
+	bi.GitCommitHash = os.Getenv("BUILD_SOURCEVERSION")
+	bi.CIBuildNumber = os.Getenv("BUILD_BUILDID")
+	bi.GitRepositoryURL = os.Getenv("BUILD_REPOSITORY_URI")
+
+	if collection := os.Getenv("SYSTEM_COLLECTIONURI"); collection != "" {
+		bi.CIBuildURL = strings.TrimSuffix(collection, "/") + "/" + url.PathEscape(os.Getenv("SYSTEM_TEAMPROJECT")) +
+			"/_build/results?buildId=" + bi.CIBuildNumber
+	}
+
+	ref := os.Getenv("BUILD_SOURCEBRANCH")
+	if strings.HasPrefix(ref, refTags) {
+		bi.GitTag = ref[len(refTags):]
+	} else {
+		bi.GitBranch = strings.TrimPrefix(ref, refHeads)
+	}
+
+	return nil
+}
+
+func (f azurePipelinesCIInfoFetcher) String() string {
+	return "azure-pipelines"
+}
+
+// buildkiteCIInfoFetcher is a fetcher for Buildkite
+// see https://buildkite.com/docs/pipelines/environment-variables
+type buildkiteCIInfoFetcher struct{}
+
+// Detect if it's a suited fetcher
+func (f buildkiteCIInfoFetcher) Detect(_ string) bool {
+	return os.Getenv("BUILDKITE") == sTrue
+}
+
+// Fetch fetches the CI information
+func (f buildkiteCIInfoFetcher) Fetch(_ string, bi *BuildInfo) error {
+	bi.GitCommitHash = os.Getenv("BUILDKITE_COMMIT")
+	bi.GitTag = os.Getenv("BUILDKITE_TAG")
+	bi.GitBranch = os.Getenv("BUILDKITE_BRANCH")
+	bi.CIBuildNumber = os.Getenv("BUILDKITE_BUILD_NUMBER")
+	bi.GitRepositoryURL = os.Getenv("BUILDKITE_REPO")
+	bi.CIBuildURL = os.Getenv("BUILDKITE_BUILD_URL")
+
+	return nil
+}
+
+func (f buildkiteCIInfoFetcher) String() string {
+	return "buildkite"
+}
+
+// npmLockfiles maps the lockfiles to the package manager that produces them
+var npmLockfiles = []struct {
+	file    string
+	manager string
+}{
+	{"pnpm-lock.yaml", "pnpm"},
+	{"yarn.lock", "yarn"},
+	{"bun.lockb", "bun"},
+	{"bun.lock", "bun"},
+	{"package-lock.json", "npm"},
+	{"npm-shrinkwrap.json", "npm"},
+}
+
+var errPackageNotFound = errors.New("package not found")
+
+type npmPackage struct {
+	Name           string          `json:"name"`
+	Version        string          `json:"version"`
+	PackageManager string          `json:"packageManager"`
+	Workspaces     json.RawMessage `json:"workspaces"`
+}
+
+func loadNpmPackage(fileName string) (*npmPackage, error) {
+	b, err := os.ReadFile(fileName) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &npmPackage{}
+
+	if err := json.Unmarshal(b, pkg); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", fileName, err)
+	}
+
+	return pkg, nil
+}
+
+// workspacePatterns returns the workspaces declared in the package.json, whether they
+// are declared as an array or as a "packages" property (yarn classic)
+func (p *npmPackage) workspacePatterns() []string {
+	if len(p.Workspaces) == 0 {
+		return nil
+	}
+
+	var patterns []string
+
+	if err := json.Unmarshal(p.Workspaces, &patterns); err == nil {
+		return patterns
+	}
+
+	var workspaces struct {
+		Packages []string `json:"packages"`
+	}
+
+	if err := json.Unmarshal(p.Workspaces, &workspaces); err != nil {
+		log.Warn("Could not parse workspaces", "err", err)
+	}
+
+	return workspaces.Packages
+}
+
 type npmInfoFetcher struct{}
 
 func (f npmInfoFetcher) Detect(dir string) bool {
@@ -220,26 +373,142 @@ func (f npmInfoFetcher) Detect(dir string) bool {
 	return !st.IsDir()
 }
 
-// Fetch parses a package.json file and retrieve the version property
+// Fetch parses a package.json file and retrieve the version property.
+// When a package was selected (bi.workspacePackage), it is looked up in the
+// npm/yarn workspaces and in the pnpm-workspace.yaml packages.
 func (f npmInfoFetcher) Fetch(dir string, bi *BuildInfo) error {
-	b, err := os.ReadFile(path.Join(dir, "package.json")) //nolint:gosec
+	root, err := loadNpmPackage(path.Join(dir, "package.json"))
 	if err != nil {
 		return err
 	}
 
-	var pkg struct {
-		Version string `json:"version"`
-	}
+	bi.PackageManager = f.packageManager(dir, root)
 
-	if err := json.Unmarshal(b, &pkg); err != nil {
-		return err
+	pkg := root
+
+	if bi.workspacePackage != "" && bi.workspacePackage != root.Name {
+		if pkg, err = f.findWorkspacePackage(dir, root, bi.workspacePackage); err != nil {
+			return err
+		}
+	} else if root.Version == "" {
+		if patterns, _ := f.workspacePatterns(dir, root); len(patterns) > 0 {
+			log.Warn("The root package has no version, you might want to select a workspace package")
+		}
 	}
 
+	bi.PackageName = pkg.Name
+	bi.PackageVersion = pkg.Version
 	bi.VersionDeclared = pkg.Version
 
 	return nil
 }
 
+// packageManager detects the package manager from the lockfile, then from the packageManager property
+func (f npmInfoFetcher) packageManager(dir string, root *npmPackage) string {
+	for _, lockfile := range npmLockfiles {
+		if _, err := os.Stat(path.Join(dir, lockfile.file)); err == nil {
+			return lockfile.manager
+		}
+	}
+
+	if root.PackageManager != "" {
+		return strings.SplitN(root.PackageManager, "@", 2)[0]
+	}
+
+	return f.String()
+}
+
+func (f npmInfoFetcher) workspacePatterns(dir string, root *npmPackage) ([]string, error) {
+	patterns := root.workspacePatterns()
+
+	b, err := os.ReadFile(path.Join(dir, "pnpm-workspace.yaml")) //nolint:gosec
+	if err != nil {
+		if os.IsNotExist(err) {
+			return patterns, nil
+		}
+
+		return nil, err
+	}
+
+	var pnpmWorkspace struct {
+		Packages []string `yaml:"packages"`
+	}
+
+	if err := yaml.Unmarshal(b, &pnpmWorkspace); err != nil {
+		return nil, fmt.Errorf("could not parse pnpm-workspace.yaml: %w", err)
+	}
+
+	return append(patterns, pnpmWorkspace.Packages...), nil
+}
+
+// workspaceDirs expands the workspace patterns, "!" prefixed patterns being exclusions
+func (f npmInfoFetcher) workspaceDirs(dir string, root *npmPackage) ([]string, error) {
+	patterns, err := f.workspacePatterns(dir, root)
+	if err != nil {
+		return nil, err
+	}
+
+	included := map[string]bool{}
+
+	var dirs []string
+
+	for _, pattern := range patterns {
+		exclude := strings.HasPrefix(pattern, "!")
+
+		matches, err := expandGlob(dir, strings.TrimPrefix(pattern, "!"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid workspace pattern %s: %w", pattern, err)
+		}
+
+		for _, match := range matches {
+			switch {
+			case exclude:
+				included[match] = false
+			case !included[match]:
+				included[match] = true
+				dirs = append(dirs, match)
+			}
+		}
+	}
+
+	result := dirs[:0]
+
+	for _, d := range dirs {
+		if included[d] {
+			result = append(result, d)
+		}
+	}
+
+	return result, nil
+}
+
+func (f npmInfoFetcher) findWorkspacePackage(dir string, root *npmPackage, name string) (*npmPackage, error) {
+	dirs, err := f.workspaceDirs(dir, root)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, workspaceDir := range dirs {
+		fileName := path.Join(dir, workspaceDir, "package.json")
+		if _, err := os.Stat(fileName); err != nil {
+			continue
+		}
+
+		pkg, err := loadNpmPackage(fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		if pkg.Name == name {
+			log.Debug("Found workspace package", "name", name, "dir", workspaceDir)
+
+			return pkg, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w in workspaces: %s", errPackageNotFound, name)
+}
+
 func (f npmInfoFetcher) String() string {
 	return "npm"
 }
@@ -315,10 +584,207 @@ func (f mavenInfoFetcher) String() string {
 	return "maven"
 }
 
+// nugetProjectPatterns are the MSBuild project files a .NET project can be declared with
+var nugetProjectPatterns = []string{"*.csproj", "*.fsproj", "*.vbproj"}
+
+const nugetBuildProps = "Directory.Build.props"
+
 type nugetInfoFetcher struct{}
 
-func (f nugetInfoFetcher) Detect(_ string) bool {
-	st, err := os.Stat("*.csproj")
+func (f nugetInfoFetcher) Detect(dir string) bool {
+	file, err := f.projectFile(dir)
+
+	return err == nil && file != ""
+}
+
+// projectFile returns the first MSBuild project file found in dir
+func (f nugetInfoFetcher) projectFile(dir string) (string, error) {
+	for _, pattern := range nugetProjectPatterns {
+		files, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return "", err
+		}
+
+		if len(files) > 0 {
+			sort.Strings(files)
+
+			return files[0], nil
+		}
+	}
+
+	return "", nil
+}
+
+// Fetch parses a *.csproj, *.fsproj or *.vbproj file and retrieve the version properties.
+// Like MSBuild, the properties of the closest Directory.Build.props file are inherited, the ones
+// above it only when it imports them.
+// Status: Should work
+func (f nugetInfoFetcher) Fetch(dir string, bi *BuildInfo) error {
+	projectFile, err := f.projectFile(dir)
+	if err != nil {
+		return err
+	}
+
+	if projectFile == "" {
+		return fmt.Errorf("unable to find any project file: %w", errCouldNotFindVersion)
+	}
+
+	props := &msbuildVersionProps{}
+
+	if file := findBuildPropsFile(dir); file != "" {
+		if err := props.load(file); err != nil {
+			return fmt.Errorf("unable to parse %s: %w", file, err)
+		}
+	}
+
+	if err := props.load(projectFile); err != nil {
+		return fmt.Errorf("unable to parse %s: %w", projectFile, err)
+	}
+
+	version := props.version()
+	if version == "" {
+		return fmt.Errorf("unable to find version in %s: %w", projectFile, errCouldNotFindVersion)
+	}
+
+	bi.VersionDeclared = version
+
+	return nil
+}
+
+func (f nugetInfoFetcher) String() string {
+	return "nuget"
+}
+
+// msbuildVersionProps contains the MSBuild properties that define the version of a project
+type msbuildVersionProps struct {
+	Version         string `xml:"Version"`
+	VersionPrefix   string `xml:"VersionPrefix"`
+	VersionSuffix   string `xml:"VersionSuffix"`
+	AssemblyVersion string `xml:"AssemblyVersion"`
+}
+
+// load reads the PropertyGroup elements of an MSBuild file, overriding the properties it declares.
+// The Directory.Build.props files it imports are read first.
+func (p *msbuildVersionProps) load(fileName string) error {
+	b, err := os.ReadFile(fileName) //nolint:gosec
+	if err != nil {
+		return err
+	}
+
+	var project struct {
+		Imports []struct {
+			Project string `xml:"Project,attr"`
+		} `xml:"Import"`
+		PropertyGroups []msbuildVersionProps `xml:"PropertyGroup"`
+	}
+
+	if err := xml.Unmarshal(b, &project); err != nil {
+		return err
+	}
+
+	for _, imported := range project.Imports {
+		if file := msbuildImportedProps(fileName, imported.Project); file != "" {
+			if err := p.load(file); err != nil {
+				return fmt.Errorf("unable to parse %s: %w", file, err)
+			}
+		}
+	}
+
+	for _, group := range project.PropertyGroups {
+		p.override(&group)
+	}
+
+	return nil
+}
+
+func (p *msbuildVersionProps) override(other *msbuildVersionProps) {
+	if v := strings.TrimSpace(other.Version); v != "" {
+		p.Version = v
+	}
+
+	if v := strings.TrimSpace(other.VersionPrefix); v != "" {
+		p.VersionPrefix = v
+	}
+
+	if v := strings.TrimSpace(other.VersionSuffix); v != "" {
+		p.VersionSuffix = v
+	}
+
+	if v := strings.TrimSpace(other.AssemblyVersion); v != "" {
+		p.AssemblyVersion = v
+	}
+}
+
+// version applies the MSBuild rules: Version wins, otherwise it's built from VersionPrefix and VersionSuffix
+func (p *msbuildVersionProps) version() string {
+	switch {
+	case p.Version != "":
+		return p.Version
+	case p.VersionPrefix != "" && p.VersionSuffix != "":
+		return p.VersionPrefix + "-" + p.VersionSuffix
+	case p.VersionPrefix != "":
+		return p.VersionPrefix
+	default:
+		return p.AssemblyVersion
+	}
+}
+
+// findBuildPropsFile returns the closest Directory.Build.props file from dir up to the root of the repository
+func findBuildPropsFile(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for i := 0; i < 20; i++ {
+		file := filepath.Join(dir, nugetBuildProps)
+		if isFile(file) {
+			return file
+		}
+
+		// We don't look further than the root of the repository
+		if st, errSt := os.Stat(filepath.Join(dir, ".git")); errSt == nil && st.IsDir() {
+			break
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+
+		dir = parent
+	}
+
+	return ""
+}
+
+// msbuildImportedProps returns the Directory.Build.props file of a parent directory imported by an MSBuild file,
+// with the usual $([MSBuild]::GetPathOfFileAbove('Directory.Build.props', ...)) or a relative path. The other
+// imports are ignored.
+func msbuildImportedProps(fileName, project string) string {
+	dir := filepath.Dir(fileName)
+	project = strings.ReplaceAll(project, `\`, "/")
+
+	switch {
+	case strings.Contains(project, "GetPathOfFileAbove") && strings.Contains(project, nugetBuildProps):
+		if parent := filepath.Dir(dir); parent != dir {
+			return findBuildPropsFile(parent)
+		}
+	case path.Base(project) == nugetBuildProps && !strings.Contains(project, "$("):
+		file := filepath.Join(dir, filepath.FromSlash(project))
+
+		// Only the parent directories are followed, so that the imports can't loop
+		if rel, err := filepath.Rel(filepath.Dir(file), dir); err == nil && rel != "." &&
+			!strings.HasPrefix(rel, "..") && isFile(file) {
+			return file
+		}
+	}
+
+	return ""
+}
+
+func isFile(fileName string) bool {
+	st, err := os.Stat(fileName)
 	if err != nil {
 		return false
 	}
@@ -326,35 +792,221 @@ func (f nugetInfoFetcher) Detect(_ string) bool {
 	return !st.IsDir()
 }
 
-// Fetch parses a *.csproj file and retrieve the version property
-// Status: Should work
-func (f nugetInfoFetcher) Fetch(_ string, bi *BuildInfo) error {
-	files, err := filepath.Glob("*.csproj")
+// getVersionFromPatterns returns the first submatch of the first matching pattern in the file
+func getVersionFromPatterns(fileName string, patterns ...*regexp.Regexp) (string, error) {
+	b, err := os.ReadFile(fileName) //nolint:gosec
+	if err != nil {
+		return "", err
+	}
+
+	for _, re := range patterns {
+		if matches := re.FindSubmatch(b); len(matches) == 2 {
+			return string(matches[1]), nil
+		}
+	}
+
+	return "", fmt.Errorf("unable to find version in %s: %w", fileName, errCouldNotFindVersion)
+}
+
+type composerInfoFetcher struct{}
+
+func (f composerInfoFetcher) Detect(dir string) bool {
+	return isFile(path.Join(dir, "composer.json"))
+}
+
+// Fetch parses a composer.json file and retrieve the version property
+func (f composerInfoFetcher) Fetch(dir string, bi *BuildInfo) error {
+	b, err := os.ReadFile(path.Join(dir, "composer.json")) //nolint:gosec
+	if err != nil {
+		return err
+	}
+
+	var composer struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+
+	if err := json.Unmarshal(b, &composer); err != nil {
+		return err
+	}
+
+	bi.PackageName = composer.Name
+	bi.PackageVersion = composer.Version
+	bi.VersionDeclared = composer.Version
+
+	return nil
+}
+
+func (f composerInfoFetcher) String() string {
+	return "composer"
+}
+
+var (
+	reGemspecVersion = regexp.MustCompile(`\.version\s*=\s*["']([^"']+)["']`)
+	reGemspecName    = regexp.MustCompile(`\.name\s*=\s*["']([^"']+)["']`)
+	reRubyVersion    = regexp.MustCompile(`VERSION\s*=\s*["']([^"']+)["']`)
+)
+
+type rubyGemsInfoFetcher struct{}
+
+func (f rubyGemsInfoFetcher) gemspec(dir string) string {
+	files, err := filepath.Glob(filepath.Join(dir, "*.gemspec"))
+	if err != nil || len(files) == 0 {
+		return ""
+	}
+
+	sort.Strings(files)
+
+	return files[0]
+}
+
+func (f rubyGemsInfoFetcher) Detect(dir string) bool {
+	return f.gemspec(dir) != ""
+}
+
+// Fetch parses a *.gemspec file and retrieve the version property. When the version is
+// a constant, it is searched in the lib/**/version.rb files.
+func (f rubyGemsInfoFetcher) Fetch(dir string, bi *BuildInfo) error {
+	gemspec := f.gemspec(dir)
+
+	b, err := os.ReadFile(gemspec) //nolint:gosec
 	if err != nil {
 		return err
 	}
 
-	if len(files) == 0 {
-		return fmt.Errorf("unable to find any csproj file: %w", errCouldNotFindVersion)
+	if matches := reGemspecName.FindSubmatch(b); len(matches) == 2 {
+		bi.PackageName = string(matches[1])
 	}
 
-	b, err := os.ReadFile(files[0])
+	if matches := reGemspecVersion.FindSubmatch(b); len(matches) == 2 {
+		bi.PackageVersion = string(matches[1])
+		bi.VersionDeclared = bi.PackageVersion
+
+		return nil
+	}
+
+	files, err := expandGlob(dir, "lib/**/version.rb")
 	if err != nil {
 		return err
 	}
 
-	re := regexp.MustCompile(`<Version>(.*)</Version>`)
-	matches := re.FindStringSubmatch(string(b))
+	for _, file := range files {
+		if version, errVersion := getVersionFromPatterns(path.Join(dir, file), reRubyVersion); errVersion == nil {
+			bi.PackageVersion = version
+			bi.VersionDeclared = version
 
-	if len(matches) != 2 {
-		return fmt.Errorf("unable to find version in %s: %w", files[0], errCouldNotFindVersion)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unable to find version in %s: %w", gemspec, errCouldNotFindVersion)
+}
+
+func (f rubyGemsInfoFetcher) String() string {
+	return "rubygems"
+}
+
+type dartInfoFetcher struct{}
+
+func (f dartInfoFetcher) Detect(dir string) bool {
+	return isFile(path.Join(dir, "pubspec.yaml"))
+}
+
+// Fetch parses a pubspec.yaml file and retrieve the version property. The build number
+// (1.2.3+45) is used by flutter for the android versionCode and the iOS CFBundleVersion.
+func (f dartInfoFetcher) Fetch(dir string, bi *BuildInfo) error {
+	b, err := os.ReadFile(path.Join(dir, "pubspec.yaml")) //nolint:gosec
+	if err != nil {
+		return err
+	}
+
+	var pubspec struct {
+		Name    string `yaml:"name"`
+		Version string `yaml:"version"`
+	}
+
+	if err := yaml.Unmarshal(b, &pubspec); err != nil {
+		return fmt.Errorf("could not parse pubspec.yaml: %w", err)
+	}
+
+	bi.PackageName = pubspec.Name
+	bi.PackageVersion = pubspec.Version
+
+	version, buildNumber, _ := strings.Cut(pubspec.Version, "+")
+	bi.VersionDeclared = version
+	bi.BuildNumber = buildNumber
+
+	return nil
+}
+
+func (f dartInfoFetcher) String() string {
+	return "pub"
+}
+
+var (
+	reMixVersion          = regexp.MustCompile(`version:\s*"([^"]+)"`)
+	reMixVersionAttribute = regexp.MustCompile(`@version\s+"([^"]+)"`)
+	reMixApp              = regexp.MustCompile(`app:\s*:(\w+)`)
+)
+
+type elixirInfoFetcher struct{}
+
+func (f elixirInfoFetcher) Detect(dir string) bool {
+	return isFile(path.Join(dir, "mix.exs"))
+}
+
+// Fetch parses a mix.exs file and retrieve the version of the project, which is often declared as a module attribute
+func (f elixirInfoFetcher) Fetch(dir string, bi *BuildInfo) error {
+	fileName := path.Join(dir, "mix.exs")
+
+	version, err := getVersionFromPatterns(fileName, reMixVersion, reMixVersionAttribute)
+	if err != nil {
+		return err
+	}
+
+	if app, errApp := getVersionFromPatterns(fileName, reMixApp); errApp == nil {
+		bi.PackageName = app
 	}
 
-	bi.Version = matches[1]
+	bi.PackageVersion = version
+	bi.VersionDeclared = version
 
 	return nil
 }
 
-func (f nugetInfoFetcher) String() string {
-	return "nuget"
+func (f elixirInfoFetcher) String() string {
+	return "mix"
+}
+
+var reCMakeProject = regexp.MustCompile(`(?is)project\s*\(\s*([^\s)]+)[^)]*?\sVERSION\s+"?([0-9][0-9.]*)`)
+
+type cmakeInfoFetcher struct{}
+
+func (f cmakeInfoFetcher) Detect(dir string) bool {
+	return isFile(path.Join(dir, "CMakeLists.txt"))
+}
+
+// Fetch parses a CMakeLists.txt file and retrieve the VERSION of the project() command
+func (f cmakeInfoFetcher) Fetch(dir string, bi *BuildInfo) error {
+	fileName := path.Join(dir, "CMakeLists.txt")
+
+	b, err := os.ReadFile(fileName) //nolint:gosec
+	if err != nil {
+		return err
+	}
+
+	matches := reCMakeProject.FindSubmatch(b)
+	if len(matches) != 3 {
+		return fmt.Errorf("unable to find version in %s: %w", fileName, errCouldNotFindVersion)
+	}
+
+	bi.PackageName = string(matches[1])
+	bi.PackageVersion = string(matches[2])
+	bi.VersionDeclared = bi.PackageVersion
+
+	return nil
+}
+
+func (f cmakeInfoFetcher) String() string {
+	return "cmake"
 }
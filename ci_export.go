@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// defaultCIExportFields are the fields exported to the CI when none is configured
+var defaultCIExportFields = []string{
+	"Version", "VersionDeclared", "GitCommitHash", "GitCommitHashShort", "GitBranch", "GitTag", "GitSmartRef",
+}
+
+// ciExportEntry is an exported field, with its output name and its variable name
+type ciExportEntry struct {
+	Name     string
+	Variable string
+	Value    string
+}
+
+// ciExporters publish the build info in a CI solution native way
+var ciExporters = map[string]func(config *Config, entries []ciExportEntry) error{
+	"github-actions":  exportGitHubActions,
+	"gitlab":          exportGitLab,
+	"azure-pipelines": exportAzurePipelines,
+	"buildkite":       exportBuildkite,
+}
+
+// exportCIOutputs publishes the selected fields to the detected CI solution
+func exportCIOutputs(config *Config, buildInfo *BuildInfo) error {
+	exporter, ok := ciExporters[buildInfo.CISolution]
+	if !ok {
+		log.Info("No CI exporter", "ciSolution", buildInfo.CISolution)
+
+		return nil
+	}
+
+	fieldNames := config.CIExport.Fields
+	if len(fieldNames) == 0 {
+		fieldNames = defaultCIExportFields
+	}
+
+	entries := make([]ciExportEntry, 0, len(fieldNames))
+
+	for _, name := range fieldNames {
+		fields, err := buildInfo.selectFields(name)
+		if err != nil {
+			return err
+		}
+
+		for _, field := range fields {
+			key := strings.ReplaceAll(field.Key, ".", "_")
+			entries = append(entries, ciExportEntry{
+				Name:     convertKeyCase(key, keyCaseSnake),
+				Variable: config.CIExport.Prefix + convertKeyCase(key, keyCaseScreaming),
+				Value:    field.Value,
+			})
+		}
+	}
+
+	log.Debug("Exporting to CI", "ciSolution", buildInfo.CISolution, "fields", len(entries))
+
+	return exporter(config, entries)
+}
+
+// appendToFile appends content to a file whose path is given by an environment variable, if it's defined
+func appendToFile(config *Config, envVar string, content []byte) error {
+	fileName := os.Getenv(envVar)
+	if fileName == "" {
+		log.Debug("Environment variable not defined", "envVar", envVar)
+
+		return nil
+	}
+
+	existing, err := config.writer().ReadFile(fileName)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not read %s: %w", envVar, err)
+	}
+
+	return config.writer().WriteFile(fileName, append(existing, content...), 0)
+}
+
+// githubCommandLine formats a line of the GitHub Actions environment files, multiline values use a delimiter
+func githubCommandLine(name, value string) string {
+	if !strings.ContainsAny(value, "\r\n") {
+		return name + "=" + value + "\n"
+	}
+
+	delimiter := "ci_info_delimiter"
+	for strings.Contains(value, delimiter) {
+		delimiter += "_"
+	}
+
+	return name + "<<" + delimiter + "\n" + value + "\n" + delimiter + "\n"
+}
+
+// exportGitHubActions sets the step outputs and the environment variables of the next steps, and adds a summary
+// see https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#environment-files
+func exportGitHubActions(config *Config, entries []ciExportEntry) error {
+	var outputs, env, summary bytes.Buffer
+
+	summary.WriteString("| Field | Value |\n| ----- | ----- |\n")
+
+	for _, entry := range entries {
+		outputs.WriteString(githubCommandLine(entry.Name, entry.Value))
+		env.WriteString(githubCommandLine(entry.Variable, entry.Value))
+
+		value := strings.ReplaceAll(strings.ReplaceAll(entry.Value, "|", `\|`), "\n", "<br>")
+		fmt.Fprintf(&summary, "| %s | `%s` |\n", entry.Name, value)
+	}
+
+	if err := appendToFile(config, "GITHUB_OUTPUT", outputs.Bytes()); err != nil {
+		return err
+	}
+
+	if err := appendToFile(config, "GITHUB_ENV", env.Bytes()); err != nil {
+		return err
+	}
+
+	// The summary is written unless it's disabled
+	if summaryEnabled := config.CIExport.Summary; summaryEnabled != nil && !*summaryEnabled {
+		return nil
+	}
+
+	return appendToFile(config, "GITHUB_STEP_SUMMARY", summary.Bytes())
+}
+
+// exportGitLab writes a dotenv report, that has to be declared as an artifact of the job:
+// artifacts: { reports: { dotenv: build.env } }
+// see https://docs.gitlab.com/ee/ci/yaml/artifacts_reports.html#artifactsreportsdotenv
+func exportGitLab(config *Config, entries []ciExportEntry) error {
+	var b bytes.Buffer
+
+	for _, entry := range entries {
+		// The dotenv reports don't support multiline values
+		if strings.ContainsAny(entry.Value, "\r\n") {
+			log.Warn("Skipping multiline value", "variable", entry.Variable)
+
+			continue
+		}
+
+		fmt.Fprintf(&b, "%s=%s\n", entry.Variable, entry.Value)
+	}
+
+	dotenvFile := config.CIExport.DotenvFile
+	if dotenvFile == "" {
+		dotenvFile = "build.env"
+	}
+
+	return config.writer().WriteFile(config.outputPath(dotenvFile), b.Bytes(), 0)
+}
+
+// exportAzurePipelines prints the logging commands setting the variables, as outputs for the next jobs
+// see https://learn.microsoft.com/en-us/azure/devops/pipelines/scripts/logging-commands
+func exportAzurePipelines(_ *Config, entries []ciExportEntry) error {
+	escaper := strings.NewReplacer("%", "%AZP25", "\r", "%0D", "\n", "%0A")
+
+	for _, entry := range entries {
+		fmt.Printf("##vso[task.setvariable variable=%s;isoutput=true]%s\n", entry.Variable, escaper.Replace(entry.Value))
+	}
+
+	return nil
+}
+
+// exportBuildkite prints the commands setting the meta-data of the build, to be evaluated by the step:
+// eval "$(ci-info --ci-export)"
+// see https://buildkite.com/docs/pipelines/build-meta-data
+func exportBuildkite(_ *Config, entries []ciExportEntry) error {
+	for _, entry := range entries {
+		fmt.Printf("buildkite-agent meta-data set %s %s\n", shellQuote(entry.Name), shellQuote(entry.Value))
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func getCIExportBuildInfo(ciSolution string) *BuildInfo {
+	return &BuildInfo{
+		CISolution:      ciSolution,
+		Version:         "1.2.3-main-f96a756",
+		VersionDeclared: "1.2.3",
+		GitBranch:       "main",
+		Custom:          map[string]string{"notes": "line 1\nline 2"},
+	}
+}
+
+func TestCIExportGitHubActions(t *testing.T) {
+	a := require.New(t)
+	dir := t.TempDir()
+
+	for _, envVar := range []string{"GITHUB_OUTPUT", "GITHUB_ENV", "GITHUB_STEP_SUMMARY"} {
+		fileName := filepath.Join(dir, envVar)
+		a.NoError(os.WriteFile(fileName, []byte("existing=1\n"), 0600))
+		t.Setenv(envVar, fileName)
+	}
+
+	config := &Config{CIExport: &ConfigCIExport{
+		Fields: []string{"Version", "GitBranch", "custom"},
+		Prefix: "APP_",
+	}}
+	a.NoError(exportCIOutputs(config, getCIExportBuildInfo("github-actions")))
+
+	content, err := os.ReadFile(filepath.Join(dir, "GITHUB_OUTPUT"))
+	a.NoError(err)
+	a.Equal("existing=1\nversion=1.2.3-main-f96a756\ngit_branch=main\n"+
+		"custom_notes<<ci_info_delimiter\nline 1\nline 2\nci_info_delimiter\n", string(content))
+
+	content, err = os.ReadFile(filepath.Join(dir, "GITHUB_ENV"))
+	a.NoError(err)
+	a.Contains(string(content), "existing=1\nAPP_VERSION=1.2.3-main-f96a756\nAPP_GIT_BRANCH=main\n")
+
+	content, err = os.ReadFile(filepath.Join(dir, "GITHUB_STEP_SUMMARY"))
+	a.NoError(err)
+	a.Contains(string(content), "| version | `1.2.3-main-f96a756` |\n")
+	a.Contains(string(content), "| custom_notes | `line 1<br>line 2` |\n")
+
+	// The summary can be disabled
+	disabled := false
+	config.CIExport.Summary = &disabled
+	a.NoError(exportCIOutputs(config, getCIExportBuildInfo("github-actions")))
+
+	again, err := os.ReadFile(filepath.Join(dir, "GITHUB_STEP_SUMMARY"))
+	a.NoError(err)
+	a.Equal(content, again)
+}
+
+// TestMainCIExportGitHubActions only sets the variables of a GitHub runner, so that the CI detection is tested too
+func TestMainCIExportGitHubActions(t *testing.T) {
+	a := require.New(t)
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, ".ci-info.json")
+
+	for envVar, value := range map[string]string{
+		"GITHUB_ACTIONS":    "true",
+		"GITHUB_ACTION":     "__run_2",
+		"GITHUB_SHA":        "f96a756e2b1c8d2a7f5e3b0c9d8e7f6a5b4c3d2e",
+		"GITHUB_REF":        "refs/tags/v1.2.3",
+		"GITHUB_RUN_ID":     "4242",
+		"GITHUB_SERVER_URL": "https://github.com",
+		"GITHUB_REPOSITORY": "fclairamb/ci-info",
+		"GITHUB_OUTPUT":     filepath.Join(dir, "GITHUB_OUTPUT"),
+	} {
+		t.Setenv(envVar, value)
+	}
+
+	a.NoError(os.WriteFile(configFile, []byte(`{
+		"version_input_git_tag": {"pattern": "^v?([0-9.]+)$"},
+		"ci_export": {"fields": ["Version", "CISolution", "CIBuildURL"]}
+	}`), 0600))
+	a.NoError(runMain([]string{"-c", configFile}))
+
+	content, err := os.ReadFile(filepath.Join(dir, "GITHUB_OUTPUT")) //nolint:gosec
+	a.NoError(err)
+	a.Equal("version=1.2.3\nci_solution=github-actions\n"+
+		"ci_build_url=https://github.com/fclairamb/ci-info/actions/runs/4242\n", string(content))
+
+	// The provenance identifies the runner
+	bi := &BuildInfo{}
+	a.NoError(fetchCISolutionInfo(dir, bi))
+
+	runDetails := newProvenanceStatement(bi, &ConfigProvenance{}, nil).Predicate.RunDetails
+	a.Equal("https://github.com/actions/runner", runDetails.Builder.ID)
+	a.Equal("https://github.com/fclairamb/ci-info/actions/runs/4242", runDetails.Metadata.InvocationID)
+}
+
+func TestCIExportGitLab(t *testing.T) {
+	a := require.New(t)
+	dir := t.TempDir()
+
+	config := &Config{Directory: dir, CIExport: &ConfigCIExport{}}
+	a.NoError(exportCIOutputs(config, getCIExportBuildInfo("gitlab")))
+
+	content, err := os.ReadFile(filepath.Join(dir, "build.env"))
+	a.NoError(err)
+	a.Equal("VERSION=1.2.3-main-f96a756\nVERSION_DECLARED=1.2.3\nGIT_HASH=\nGIT_COMMIT_HASH_SHORT=\n"+
+		"GIT_BRANCH=main\nGIT_TAG=\nGIT_SMART_REF=\n", string(content))
+}
+
+func TestCIExportAzureAndBuildkite(t *testing.T) {
+	a := require.New(t)
+	config := &Config{CIExport: &ConfigCIExport{Fields: []string{"VersionDeclared", "custom"}}}
+
+	output, err := captureStdout(t, func() error {
+		return exportCIOutputs(config, getCIExportBuildInfo("azure-pipelines"))
+	})
+	a.NoError(err)
+	a.Equal("##vso[task.setvariable variable=VERSION_DECLARED;isoutput=true]1.2.3\n"+
+		"##vso[task.setvariable variable=CUSTOM_NOTES;isoutput=true]line 1%0Aline 2\n", output)
+
+	output, err = captureStdout(t, func() error {
+		return exportCIOutputs(config, getCIExportBuildInfo("buildkite"))
+	})
+	a.NoError(err)
+	a.Equal("buildkite-agent meta-data set 'version_declared' '1.2.3'\n"+
+		"buildkite-agent meta-data set 'custom_notes' 'line 1\nline 2'\n", output)
+
+	// Unsupported CI solutions are ignored
+	a.NoError(exportCIOutputs(config, getCIExportBuildInfo("travis")))
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"os"
+	"path"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type testInfo struct {
@@ -18,7 +21,7 @@ func getTestsInfo() []testInfo {
 		{
 			CISolution: "github-actions",
 			EnvCommon: map[string]string{
-				"GITHUB_ACTION": "true",
+				"GITHUB_ACTIONS": "true",
 			},
 			EnvTag: map[string]string{
 				"GITHUB_REF": "refs/tags/v1.2.3",
@@ -88,6 +91,30 @@ func getTestsInfo() []testInfo {
 				"DRONE_BRANCH": "feature/cool-one",
 			},
 		},
+		{
+			CISolution: "azure-pipelines",
+			EnvCommon: map[string]string{
+				"TF_BUILD": "True",
+			},
+			EnvTag: map[string]string{
+				"BUILD_SOURCEBRANCH": "refs/tags/v1.2.3",
+			},
+			EnvBranch: map[string]string{
+				"BUILD_SOURCEBRANCH": "refs/heads/feature/cool-one",
+			},
+		},
+		{
+			CISolution: "buildkite",
+			EnvCommon: map[string]string{
+				"BUILDKITE": "true",
+			},
+			EnvTag: map[string]string{
+				"BUILDKITE_TAG": "v1.2.3",
+			},
+			EnvBranch: map[string]string{
+				"BUILDKITE_BRANCH": "feature/cool-one",
+			},
+		},
 	}
 }
 
@@ -96,6 +123,9 @@ func TestGithubActions(t *testing.T) {
 
 	for _, test := range getTestsInfo() {
 		t.Run(test.CISolution, func(t *testing.T) {
+			// The tests may run on a GitHub runner
+			t.Setenv("GITHUB_ACTIONS", "")
+
 			for k, v := range test.EnvCommon {
 				t.Setenv(k, v)
 			}
@@ -139,3 +169,154 @@ func TestGithubActions(t *testing.T) {
 		})
 	}
 }
+
+func TestPackageManagerNuget(t *testing.T) {
+	t.Run("prefix and suffix from props", func(t *testing.T) {
+		a := require.New(t)
+		bi := &BuildInfo{}
+		a.NoError(fetchPackageManagerInfo("testdata/nuget/src/App", bi))
+		a.Equal("nuget", bi.PackageManager)
+		a.Equal("2.4.0-beta1", bi.VersionDeclared)
+	})
+
+	t.Run("version in project", func(t *testing.T) {
+		a := require.New(t)
+		bi := &BuildInfo{}
+		a.NoError(fetchPackageManagerInfo("testdata/nuget/src/Lib", bi))
+		a.Equal("nuget", bi.PackageManager)
+		a.Equal("3.1.4", bi.VersionDeclared)
+	})
+
+	t.Run("no project", func(t *testing.T) {
+		a := require.New(t)
+		bi := &BuildInfo{}
+		a.NoError(fetchPackageManagerInfo("testdata/nuget/src", bi))
+		a.Empty(bi.PackageManager)
+	})
+
+	// Like MSBuild, only the closest props file is read, the parent ones being read if they're imported
+	t.Run("nested props", func(t *testing.T) {
+		a := require.New(t)
+		dir := t.TempDir()
+		appDir := path.Join(dir, "src", "App")
+		a.NoError(os.MkdirAll(path.Join(dir, ".git"), 0750))
+		a.NoError(os.MkdirAll(appDir, 0750))
+		a.NoError(os.WriteFile(path.Join(appDir, "App.csproj"), []byte(`<Project Sdk="Microsoft.NET.Sdk" />`), 0600))
+		a.NoError(os.WriteFile(path.Join(dir, "Directory.Build.props"), []byte(
+			"<Project><PropertyGroup><Version>1.0.0</Version></PropertyGroup></Project>",
+		), 0600))
+
+		for _, test := range []struct {
+			imported string
+			expected string
+		}{
+			{"", "2.0.0"},
+			{`<Import Project="$([MSBuild]::GetPathOfFileAbove('Directory.Build.props', '$(MSBuildThisFileDirectory)../'))" />`, "1.0.0"},
+			{`<Import Project="..\Directory.Build.props" />`, "1.0.0"},
+			{`<Import Project="Directory.Build.props" />`, "2.0.0"},
+		} {
+			a.NoError(os.WriteFile(path.Join(dir, "src", "Directory.Build.props"), []byte(
+				"<Project>"+test.imported+"<PropertyGroup><VersionPrefix>2.0.0</VersionPrefix></PropertyGroup></Project>",
+			), 0600))
+
+			bi := &BuildInfo{}
+			a.NoError(fetchPackageManagerInfo(appDir, bi))
+			a.Equal(test.expected, bi.VersionDeclared, test.imported)
+		}
+	})
+}
+
+func TestPackageManagerNpmWorkspaces(t *testing.T) {
+	t.Run("pnpm", func(t *testing.T) {
+		a := require.New(t)
+		bi := &BuildInfo{workspacePackage: "@acme/web"}
+		a.NoError(fetchPackageManagerInfo("testdata/pnpm", bi))
+		a.Equal("pnpm", bi.PackageManager)
+		a.Equal("@acme/web", bi.PackageName)
+		a.Equal("4.5.6", bi.VersionDeclared)
+	})
+
+	t.Run("pnpm excluded", func(t *testing.T) {
+		a := require.New(t)
+		bi := &BuildInfo{workspacePackage: "@acme/legacy"}
+		a.ErrorIs(fetchPackageManagerInfo("testdata/pnpm", bi), errPackageNotFound)
+	})
+
+	t.Run("pnpm root", func(t *testing.T) {
+		a := require.New(t)
+		bi := &BuildInfo{}
+		a.NoError(fetchPackageManagerInfo("testdata/pnpm", bi))
+		a.Equal("monorepo", bi.PackageName)
+		a.Empty(bi.VersionDeclared)
+	})
+
+	t.Run("npm", func(t *testing.T) {
+		a := require.New(t)
+		bi := &BuildInfo{workspacePackage: "cli"}
+		a.NoError(fetchPackageManagerInfo("testdata/npm-workspaces", bi))
+		a.Equal("npm", bi.PackageManager)
+		a.Equal("2.1.0", bi.PackageVersion)
+		a.Equal("2.1.0", bi.VersionDeclared)
+	})
+
+	// The selected package doesn't become the name of the other package managers' packages
+	t.Run("other package manager", func(t *testing.T) {
+		a := require.New(t)
+		bi := &BuildInfo{}
+		a.NoError(bi.loadVersion(&Config{Directory: "testdata/nuget/src/App", Package: "@acme/web"}))
+		a.Equal("nuget", bi.PackageManager)
+		a.NotEqual("@acme/web", bi.PackageName)
+	})
+}
+
+func TestPackageManagerNpmLockfiles(t *testing.T) {
+	for lockfile, manager := range map[string]string{
+		"":               "npm",
+		"yarn.lock":      "yarn",
+		"bun.lockb":      "bun",
+		"pnpm-lock.yaml": "pnpm",
+	} {
+		t.Run(manager, func(t *testing.T) {
+			a := require.New(t)
+			dir := t.TempDir()
+			a.NoError(os.WriteFile(path.Join(dir, "package.json"), []byte(`{"name": "app", "version": "1.0.0"}`), 0600))
+
+			if lockfile != "" {
+				a.NoError(os.WriteFile(path.Join(dir, lockfile), []byte{}, 0600))
+			}
+
+			bi := &BuildInfo{}
+			a.NoError(fetchPackageManagerInfo(dir, bi))
+			a.Equal(manager, bi.PackageManager)
+			a.Equal("1.0.0", bi.VersionDeclared)
+		})
+	}
+}
+
+func TestPackageManagerEcosystems(t *testing.T) {
+	for _, test := range []struct {
+		dir            string
+		packageManager string
+		packageName    string
+		version        string
+		buildNumber    string
+	}{
+		{"testdata/composer", "composer", "acme/api", "5.2.0", ""},
+		{"testdata/rubygems", "rubygems", "acme", "0.9.2", ""},
+		{"testdata/rubygems-inline", "rubygems", "tool", "1.4.0", ""},
+		{"testdata/flutter", "pub", "acme_app", "1.2.3", "45"},
+		{"testdata/elixir", "mix", "acme", "0.12.1", ""},
+		{"testdata/cmake", "cmake", "acme", "3.2.1", ""},
+	} {
+		test := test
+		t.Run(test.packageManager, func(t *testing.T) {
+			a := require.New(t)
+			bi := &BuildInfo{}
+			a.NoError(fetchPackageManagerInfo(test.dir, bi))
+			a.Equal(test.packageManager, bi.PackageManager)
+			a.Equal(test.packageName, bi.PackageName)
+			a.Equal(test.version, bi.VersionDeclared)
+			a.Equal(test.buildNumber, bi.BuildNumber)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	errUnknownCommand  = errors.New("unknown command")
+	errUnknownCheck    = errors.New("unknown check")
+	errMissingArgument = errors.New("missing argument")
+)
+
+// The checks of the check command
+const (
+	checkVersion = "version"
+	checkOutputs = "outputs"
+)
+
+// runCommand runs the command given as first argument, it replaces the generation of the output files
+func runCommand(params *CmdParams, config *Config) error {
+	switch params.Command {
+	case cmdCheck:
+		return runCheck(config, params.Args)
+	case cmdPrint:
+		return runPrint(config, params.Args)
+	case cmdGet:
+		return runGet(config, params.Args)
+	case cmdEnv:
+		return runEnv(config)
+	case cmdLDFlags:
+		return runLDFlags(config, params.Args)
+	case cmdDocker:
+		return runDocker(config, params.Args)
+	case cmdProvenance:
+		return runProvenance(config, params.Args)
+	case cmdVerify:
+		return runVerify(config, params.Args)
+	default:
+		return fmt.Errorf("%w: %s", errUnknownCommand, params.Command)
+	}
+}
+
+// runCheck fails if the version sources don't match ("check" or "check version"), or if the output files are out
+// of date ("check outputs" or the --check flag). It's meant to be run on CI pipelines.
+func runCheck(config *Config, args []string) error {
+	check := checkVersion
+	if len(args) > 0 {
+		check = args[0]
+	}
+
+	switch check {
+	case checkVersion:
+		config.VersionCheck = &ConfigVersionCheck{Severity: severityError}
+	case checkOutputs:
+	default:
+		return fmt.Errorf("%w: %s", errUnknownCheck, check)
+	}
+
+	// A version mismatch is reported in the returned error
+	buildInfo, err := generateBuildInfo(config)
+	if err != nil {
+		return err
+	}
+
+	if check == checkOutputs {
+		return checkOutputFiles(config, buildInfo)
+	}
+
+	for _, source := range buildInfo.versionSources {
+		fmt.Printf("%s: %s\n", source.Name, source.Version)
+	}
+
+	return nil
+}
+
+// runPrint prints a template of the config, designated by its output file, a builtin template or
+// the rendering of its argument
+func runPrint(config *Config, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: template, output file or builtin template", errMissingArgument)
+	}
+
+	buildInfo, err := generateBuildInfo(config)
+	if err != nil {
+		return err
+	}
+
+	tpl := &ConfigTemplate{InputContent: args[0]}
+
+	for _, t := range config.Templates {
+		if t.outputFileName() == args[0] {
+			tpl = t
+
+			break
+		}
+	}
+
+	if tpl.InputContent == args[0] {
+		if _, err := getBuiltinTemplate(args[0]); err == nil {
+			tpl = &ConfigTemplate{Builtin: args[0]}
+		}
+	}
+
+	var content string
+
+	if tpl.updateInPlace() {
+		original, errRead := config.writer().ReadFile(config.outputPath(tpl.OutputFile))
+		if errRead != nil {
+			return fmt.Errorf("could not read file to update: %w", errRead)
+		}
+
+		content, err = tpl.updateContent(config, string(original), buildInfo)
+	} else {
+		var templateString string
+		if templateString, err = tpl.load(config.Directory); err != nil {
+			return err
+		}
+
+		content, err = tpl.render(config, templateString, buildInfo)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(content)
+
+	return nil
+}
+
+// runGet prints a field of the build info, or all of them: VERSION=$(ci-info get version)
+func runGet(config *Config, args []string) error {
+	buildInfo, err := generateBuildInfo(config)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		for _, field := range buildInfo.fields() {
+			fmt.Printf("%s=%s\n", field.Key, field.Value)
+		}
+
+		return nil
+	}
+
+	for _, name := range args {
+		value, err := buildInfo.fieldValue(name)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(value)
+	}
+
+	return nil
+}
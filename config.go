@@ -26,11 +26,128 @@ type ConfigVersionInputEnvVar struct {
 	Pattern string `json:"pattern"`
 }
 
+// ConfigVersionCheck defines how the consistency of the version sources is checked
+type ConfigVersionCheck struct {
+	Severity string `json:"severity,omitempty"`
+}
+
+// ConfigCheck defines how the output files are checked
+type ConfigCheck struct {
+	Ignore []string `json:"ignore,omitempty"`
+}
+
+// ConfigCIExport defines the fields published to the CI solution
+type ConfigCIExport struct {
+	Fields     []string `json:"fields,omitempty"`
+	Prefix     string   `json:"prefix,omitempty"`
+	Summary    *bool    `json:"summary,omitempty"`
+	DotenvFile string   `json:"dotenv_file,omitempty"`
+}
+
+// ConfigShellEnv defines the statements printed by the env command
+type ConfigShellEnv struct {
+	Shell  string   `json:"shell,omitempty"`
+	Prefix *string  `json:"prefix,omitempty"`
+	Fields []string `json:"fields,omitempty"`
+}
+
+// ConfigLDFlags defines the go variables set by the ldflags command
+type ConfigLDFlags struct {
+	Package string            `json:"package,omitempty"`
+	Symbols map[string]string `json:"symbols,omitempty"`
+}
+
+// ConfigDocker defines the labels and build arguments printed by the docker command
+type ConfigDocker struct {
+	Labels    map[string]string `json:"labels,omitempty"`
+	BuildArgs map[string]string `json:"build_args,omitempty"`
+	Target    string            `json:"target,omitempty"`
+}
+
+// ConfigProvenance defines the provenance statement written by the provenance command
+type ConfigProvenance struct {
+	Subjects   []string `json:"subjects,omitempty"`
+	OutputFile string   `json:"output_file,omitempty"`
+	BuilderID  string   `json:"builder_id,omitempty"`
+}
+
+// ConfigSigning defines how the build info file is signed
+type ConfigSigning struct {
+	Key       string `json:"key,omitempty"`
+	PublicKey string `json:"public_key,omitempty"`
+	Format    string `json:"format,omitempty"`
+}
+
 // ConfigTemplate defines the template configuration
 type ConfigTemplate struct {
-	InputFile    string `json:"input_file,omitempty"`
-	InputContent string `json:"input_content,omitempty"`
-	OutputFile   string `json:"output_file"`
+	InputFile    string              `json:"input_file,omitempty"`
+	InputContent string              `json:"input_content,omitempty"`
+	InputFiles   string              `json:"input_files,omitempty"`
+	Builtin      string              `json:"builtin,omitempty"`
+	Options      map[string]string   `json:"options,omitempty"`
+	OutputFile   string              `json:"output_file,omitempty"`
+	Language     string              `json:"language,omitempty"`
+	Mode         string              `json:"mode,omitempty"`
+	Delims       []string            `json:"delims,omitempty"`
+	MissingKey   string              `json:"missingkey,omitempty"`
+	Strict       bool                `json:"strict,omitempty"`
+	Partials     []string            `json:"partials,omitempty"`
+	Variables    map[string]string   `json:"variables,omitempty"`
+	Regions      map[string]string   `json:"regions,omitempty"`
+	Replacements []ConfigReplacement `json:"replacements,omitempty"`
+}
+
+// ConfigReplacement replaces the matches of a pattern, or of its first group, with a rendered value
+type ConfigReplacement struct {
+	Pattern string `json:"pattern"`
+	Value   string `json:"value"`
+}
+
+// ConfigHelm defines how the version is written to a helm chart
+type ConfigHelm struct {
+	Chart      string `json:"chart,omitempty"`
+	ValuesFile string `json:"values_file,omitempty"`
+	ImageTag   string `json:"image_tag,omitempty"`
+}
+
+// ConfigYAMLValue defines a value to write at a given path of a YAML file
+type ConfigYAMLValue struct {
+	File  string `json:"file"`
+	Path  string `json:"path"`
+	Value string `json:"value,omitempty"`
+}
+
+// ConfigPlist defines the string values to set in an Info.plist file
+type ConfigPlist struct {
+	File   string            `json:"file"`
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// ConfigAndroid defines how the versionCode and versionName of an Android app are written
+type ConfigAndroid struct {
+	VersionCode       string   `json:"version_code,omitempty"`
+	VersionCodeOffset int      `json:"version_code_offset,omitempty"`
+	VersionName       string   `json:"version_name,omitempty"`
+	PropertiesFile    string   `json:"properties_file,omitempty"`
+	GradleFiles       []string `json:"gradle_files,omitempty"`
+}
+
+// ConfigDebian defines the entry added to a debian changelog
+type ConfigDebian struct {
+	Changelog    string `json:"changelog,omitempty"`
+	Package      string `json:"package,omitempty"`
+	Maintainer   string `json:"maintainer,omitempty"`
+	Distribution string `json:"distribution,omitempty"`
+	Urgency      string `json:"urgency,omitempty"`
+
+	// Revision is the debian revision, "1" by default. An empty revision is the one of the native packages.
+	Revision *string `json:"revision,omitempty"`
+}
+
+// ConfigRPM defines the spec files whose Version and Release are set
+type ConfigRPM struct {
+	SpecFiles []string `json:"spec_files,omitempty"`
+	Release   string   `json:"release,omitempty"`
 }
 
 // Config defines the configuration for ci-info
@@ -38,10 +155,52 @@ type Config struct {
 	InputVersionFile   ConfigVersionInputFile   `json:"version_input_file"`
 	InputVersionTag    ConfigVersionInputTag    `json:"version_input_git_tag"`
 	InputVersionEnvVar ConfigVersionInputEnvVar `json:"version_input_env_var"`
+	VersionCheck       *ConfigVersionCheck      `json:"version_check,omitempty"`
+	Check              *ConfigCheck             `json:"check,omitempty"`
 	Templates          []*ConfigTemplate        `json:"templates,omitempty"`
+	Partials           []string                 `json:"partials,omitempty"`
+	Variables          map[string]string        `json:"variables,omitempty"`
+	EnvVars            []string                 `json:"env_vars,omitempty"`
 	BuildInfoFile      string                   `json:"build_info_file,omitempty"`
+	BuildInfoFormat    string                   `json:"build_info_format,omitempty"`
+	BuildInfoFields    []string                 `json:"build_info_fields,omitempty"`
+	BuildInfoKeyCase   string                   `json:"build_info_key_case,omitempty"`
 	GitCmdMode         bool                     `json:"git_cmd_mode,omitempty"`
 	Directory          string                   `json:"directory,omitempty"`
+	Package            string                   `json:"package,omitempty"`
+	Helm               *ConfigHelm              `json:"helm,omitempty"`
+	YAMLValues         []*ConfigYAMLValue       `json:"yaml_values,omitempty"`
+	Plists             []*ConfigPlist           `json:"plists,omitempty"`
+	Android            *ConfigAndroid           `json:"android,omitempty"`
+	Debian             *ConfigDebian            `json:"debian,omitempty"`
+	RPM                *ConfigRPM               `json:"rpm,omitempty"`
+	CIExport           *ConfigCIExport          `json:"ci_export,omitempty"`
+	ShellEnv           *ConfigShellEnv          `json:"shell_env,omitempty"`
+	LDFlags            *ConfigLDFlags           `json:"ldflags,omitempty"`
+	Docker             *ConfigDocker            `json:"docker,omitempty"`
+	Provenance         *ConfigProvenance        `json:"provenance,omitempty"`
+	Signing            *ConfigSigning           `json:"signing,omitempty"`
+
+	// output is where the output files are written, the disk by default
+	output outputWriter
+}
+
+// outputPath returns the path of an output file, "-" being the standard output
+func (c *Config) outputPath(fileName string) string {
+	if fileName == stdoutPath {
+		return stdoutPath
+	}
+
+	return filepath.Join(c.Directory, fileName)
+}
+
+// writer returns where the output files are written
+func (c *Config) writer() outputWriter {
+	if c.output == nil {
+		return diskOutput{}
+	}
+
+	return c.output
 }
 
 const defaultConfigFile = ".ci-info.json"
@@ -141,9 +300,13 @@ func createDefaultConfig() *Config {
 			EnvVar:  "VERSION",
 			Pattern: "^([0-9.]+)$",
 		},
+		VersionCheck: &ConfigVersionCheck{
+			Severity: severityWarn,
+		},
 		Templates: []*ConfigTemplate{{
 			InputFile:  "build.go.tpl",
 			OutputFile: "build.go",
+			Language:   "go",
 		}},
 		BuildInfoFile: "build.json",
 	}
@@ -151,6 +314,18 @@ func createDefaultConfig() *Config {
 
 func saveDefaultConfig(params *CmdParams) error {
 	config := createDefaultConfig()
+
+	if params.InitTemplates != "" {
+		templates, err := getBuiltinConfigTemplates(params.InitTemplates)
+		if err != nil {
+			return err
+		}
+
+		config.Templates = templates
+	} else {
+		printBuiltinTemplates()
+	}
+
 	jsonContent, err := json.MarshalIndent(config, "", "  ")
 
 	if err != nil {
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// schemaViolations returns where value doesn't follow the schema. Only the keywords of config-schema.json are
+// supported.
+func schemaViolations(schema map[string]interface{}, value interface{}, at string) []string {
+	if types, ok := schema["type"]; ok && !schemaTypeMatches(types, value) {
+		return []string{fmt.Sprintf("%s: not of type %v", at, types)}
+	}
+
+	var violations []string
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+
+		for _, allowed := range enum {
+			found = found || reflect.DeepEqual(allowed, value)
+		}
+
+		if !found {
+			violations = append(violations, fmt.Sprintf("%s: %v not in %v", at, value, enum))
+		}
+	}
+
+	if pattern, ok := schema["pattern"].(string); ok {
+		if s, isString := value.(string); isString && !regexp.MustCompile(pattern).MatchString(s) {
+			violations = append(violations, fmt.Sprintf("%s: %q doesn't match %s", at, s, pattern))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		violations = append(violations, objectSchemaViolations(schema, v, at)...)
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				violations = append(violations, schemaViolations(items, item, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+
+		if minItems, ok := schema["minItems"].(float64); ok && float64(len(v)) < minItems {
+			violations = append(violations, fmt.Sprintf("%s: less than %v items", at, minItems))
+		}
+
+		if maxItems, ok := schema["maxItems"].(float64); ok && float64(len(v)) > maxItems {
+			violations = append(violations, fmt.Sprintf("%s: more than %v items", at, maxItems))
+		}
+	}
+
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		if matches := countSchemaMatches(oneOf, value); matches != 1 {
+			violations = append(violations, fmt.Sprintf("%s: matches %d schemas of oneOf", at, matches))
+		}
+	}
+
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok && countSchemaMatches(anyOf, value) == 0 {
+		violations = append(violations, fmt.Sprintf("%s: matches no schema of anyOf", at))
+	}
+
+	if not, ok := schema["not"].(map[string]interface{}); ok && len(schemaViolations(not, value, at)) == 0 {
+		violations = append(violations, fmt.Sprintf("%s: matches the not schema", at))
+	}
+
+	return violations
+}
+
+func objectSchemaViolations(schema map[string]interface{}, object map[string]interface{}, at string) []string {
+	var violations []string
+
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if _, found := object[name.(string)]; !found {
+				violations = append(violations, fmt.Sprintf("%s: missing %s", at, name))
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+
+	for _, name := range sortedKeys(object) {
+		if property, ok := properties[name].(map[string]interface{}); ok {
+			violations = append(violations, schemaViolations(property, object[name], at+"."+name)...)
+
+			continue
+		}
+
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				violations = append(violations, fmt.Sprintf("%s: unknown property %s", at, name))
+			}
+		case map[string]interface{}:
+			violations = append(violations, schemaViolations(additional, object[name], at+"."+name)...)
+		}
+	}
+
+	return violations
+}
+
+func countSchemaMatches(schemas []interface{}, value interface{}) int {
+	matches := 0
+
+	for _, schema := range schemas {
+		if len(schemaViolations(schema.(map[string]interface{}), value, "")) == 0 {
+			matches++
+		}
+	}
+
+	return matches
+}
+
+func schemaTypeMatches(types interface{}, value interface{}) bool {
+	if list, ok := types.([]interface{}); ok {
+		for _, t := range list {
+			if schemaTypeMatches(t, value) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	switch v := value.(type) {
+	case string:
+		return types == "string"
+	case bool:
+		return types == "boolean"
+	case float64:
+		return types == "number" || (types == "integer" && v == math.Trunc(v))
+	case []interface{}:
+		return types == "array"
+	case map[string]interface{}:
+		return types == "object"
+	case nil:
+		return types == "null"
+	}
+
+	return false
+}
+
+func loadConfigSchema(t *testing.T) map[string]interface{} {
+	t.Helper()
+
+	content, err := os.ReadFile("config-schema.json")
+	require.NoError(t, err)
+
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal(content, &schema))
+
+	return schema
+}
+
+func configSchemaViolations(t *testing.T, schema map[string]interface{}, content []byte) []string {
+	t.Helper()
+
+	var config interface{}
+	require.NoError(t, json.Unmarshal(content, &config))
+
+	return schemaViolations(schema, config, "$")
+}
+
+func TestConfigSchemaFiles(t *testing.T) {
+	a := require.New(t)
+	schema := loadConfigSchema(t)
+
+	var files []string
+
+	a.NoError(filepath.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.Name() == ".git" {
+			return err
+		}
+
+		if d.Name() == ".ci-info.json" {
+			files = append(files, path)
+		}
+
+		return nil
+	}))
+
+	a.Contains(files, filepath.Join("testdata", "regions", ".ci-info.json"))
+
+	for _, file := range files {
+		content, err := os.ReadFile(file) //nolint:gosec
+		a.NoError(err)
+		a.Empty(configSchemaViolations(t, schema, content), file)
+	}
+
+	// The generated config too
+	configFile := filepath.Join(t.TempDir(), ".ci-info.json")
+	a.NoError(runMain([]string{"-i", "-c", configFile, "-t", "go"}))
+
+	content, err := os.ReadFile(configFile) //nolint:gosec
+	a.NoError(err)
+	a.Empty(configSchemaViolations(t, schema, content))
+}
+
+func TestConfigSchemaTemplates(t *testing.T) {
+	a := require.New(t)
+	schema := loadConfigSchema(t)
+
+	for _, template := range []string{
+		`{"input_file": "build.go.tpl", "output_file": "build.go"}`,
+		`{"input_content": "{{ .Version }}", "output_file": "version.txt"}`,
+		`{"input_files": "templates/*.tpl", "output_file": "generated/**"}`,
+		`{"builtin": "go"}`,
+		`{"output_file": "README.md", "regions": {"version": "{{ .Version }}"}}`,
+		`{"output_file": "Info.plist", "replacements": [{"pattern": "x", "value": "y"}]}`,
+		`{"output_file": "a", "regions": {"v": "x"}, "replacements": [{"pattern": "x", "value": "y"}]}`,
+	} {
+		a.Empty(configSchemaViolations(t, schema, []byte(`{"templates": [`+template+`]}`)), template)
+	}
+
+	for _, template := range []string{
+		`{"output_file": "version.txt"}`,
+		`{"input_file": "a.tpl", "input_content": "x", "output_file": "a"}`,
+		`{"regions": {"version": "{{ .Version }}"}}`,
+		`{"input_content": "{{ .Version }}", "output_file": "version.txt", "unknown": true}`,
+	} {
+		a.NotEmpty(configSchemaViolations(t, schema, []byte(`{"templates": [`+template+`]}`)), template)
+	}
+}
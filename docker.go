@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var errUnknownDockerFormat = errors.New("unknown docker format, expected args, bake, bake-json or dockerfile")
+
+const (
+	dockerFormatArgs       = "args"
+	dockerFormatBake       = "bake"
+	dockerFormatBakeJSON   = "bake-json"
+	dockerFormatDockerfile = "dockerfile"
+
+	defaultDockerBakeTarget = "ci-info"
+)
+
+// ociLabels are the annotations of the OCI image spec filled from the build info,
+// see https://github.com/opencontainers/image-spec/blob/main/annotations.md
+var ociLabels = map[string]string{
+	"org.opencontainers.image.created":  "BuildDate",
+	"org.opencontainers.image.version":  "Version",
+	"org.opencontainers.image.revision": "GitCommitHash",
+	"org.opencontainers.image.source":   "GitRepositoryURL",
+	"org.opencontainers.image.ref.name": "GitRef",
+	"org.opencontainers.image.title":    "PackageName",
+}
+
+// defaultDockerBuildArgs are the build arguments passed when none is configured
+var defaultDockerBuildArgs = map[string]string{
+	"VERSION":    "Version",
+	"GIT_COMMIT": "GitCommitHash",
+	"BUILD_DATE": "BuildDate",
+}
+
+// reDockerArgSafe matches the values that don't need to be quoted. The other ones are quoted for the shell, so the
+// arguments must go through eval: eval "docker build $(ci-info docker) ."
+var reDockerArgSafe = regexp.MustCompile(`^[a-zA-Z0-9_.,:/@%+=-]+$`)
+
+// runDocker prints the labels and build arguments of a docker build, as command line arguments,
+// as a docker bake target or as a Dockerfile LABEL instruction. They are written to the file given
+// as second argument if any.
+func runDocker(config *Config, args []string) error {
+	format := dockerFormatArgs
+	if len(args) > 0 {
+		format = args[0]
+	}
+
+	outputFile := stdoutPath
+	if len(args) > 1 {
+		outputFile = args[1]
+	}
+
+	docker := ConfigDocker{}
+	if config.Docker != nil {
+		docker = *config.Docker
+	}
+
+	buildInfo, err := generateBuildInfo(config)
+	if err != nil {
+		return err
+	}
+
+	labels, err := dockerValues(buildInfo, ociLabels, docker.Labels)
+	if err != nil {
+		return err
+	}
+
+	buildArgs := docker.BuildArgs
+	if len(buildArgs) == 0 {
+		buildArgs = defaultDockerBuildArgs
+	}
+
+	buildArgValues, err := dockerValues(buildInfo, buildArgs, nil)
+	if err != nil {
+		return err
+	}
+
+	target := docker.Target
+	if target == "" {
+		target = defaultDockerBakeTarget
+	}
+
+	var content string
+
+	switch format {
+	case dockerFormatArgs:
+		content = dockerArgs(labels, buildArgValues)
+	case dockerFormatBake:
+		content = dockerBakeHCL(target, labels, buildArgValues)
+	case dockerFormatBakeJSON:
+		content, err = dockerBakeJSON(target, labels, buildArgValues)
+	case dockerFormatDockerfile:
+		content = dockerfileLabels(labels)
+	default:
+		return fmt.Errorf("%w: %s", errUnknownDockerFormat, format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return config.writer().WriteFile(config.outputPath(outputFile), []byte(content), 0)
+}
+
+// dockerValues returns the value of the field of each name, the overrides being applied on top of the
+// defaults. An empty field removes a name, and names with an empty value are skipped.
+func dockerValues(buildInfo *BuildInfo, defaults, overrides map[string]string) (map[string]string, error) {
+	fields := map[string]string{}
+
+	for name, field := range defaults {
+		fields[name] = field
+	}
+
+	for name, field := range overrides {
+		fields[name] = field
+	}
+
+	values := map[string]string{}
+
+	for name, field := range fields {
+		if field == "" {
+			continue
+		}
+
+		value, err := buildInfo.fieldValue(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid docker value %s: %w", name, err)
+		}
+
+		if value != "" {
+			values[name] = value
+		}
+	}
+
+	return values, nil
+}
+
+// dockerArgs returns the arguments of docker build: --label k=v --build-arg K=V
+func dockerArgs(labels, buildArgs map[string]string) string {
+	args := make([]string, 0, len(labels)+len(buildArgs))
+
+	for _, name := range sortedKeys(labels) {
+		args = append(args, "--label "+dockerArgQuote(name+"="+labels[name]))
+	}
+
+	for _, name := range sortedKeys(buildArgs) {
+		args = append(args, "--build-arg "+dockerArgQuote(name+"="+buildArgs[name]))
+	}
+
+	return strings.Join(args, " ") + "\n"
+}
+
+func dockerArgQuote(s string) string {
+	if reDockerArgSafe.MatchString(s) {
+		return s
+	}
+
+	return shellQuote(s)
+}
+
+// dockerBakeHCL returns a bake target that the other targets can inherit from
+func dockerBakeHCL(target string, labels, buildArgs map[string]string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "target \"%s\" {\n", hclString(target))
+
+	for _, block := range []struct {
+		name   string
+		values map[string]string
+	}{{"labels", labels}, {"args", buildArgs}} {
+		if len(block.values) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "  %s = {\n", block.name)
+
+		for _, name := range sortedKeys(block.values) {
+			fmt.Fprintf(&b, "    \"%s\" = \"%s\"\n", hclString(name), hclString(block.values[name]))
+		}
+
+		b.WriteString("  }\n")
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// hclString escapes a value for an HCL quoted string, in which "${" and "%{" start templates
+func hclString(s string) string {
+	return strings.NewReplacer("${", "$${", "%{", "%%{").Replace(jsonString(s))
+}
+
+// dockerBakeJSON returns a bake target in the JSON format of bake files
+func dockerBakeJSON(target string, labels, buildArgs map[string]string) (string, error) {
+	bakeTarget := map[string]map[string]string{}
+
+	if len(labels) > 0 {
+		bakeTarget["labels"] = labels
+	}
+
+	if len(buildArgs) > 0 {
+		bakeTarget["args"] = buildArgs
+	}
+
+	content, err := json.MarshalIndent(map[string]interface{}{
+		"target": map[string]interface{}{target: bakeTarget},
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(content) + "\n", nil
+}
+
+// dockerfileLabels returns a LABEL instruction with a label per line
+func dockerfileLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	lines := make([]string, 0, len(labels))
+
+	// Variables are expanded in the LABEL instructions, and values can't span several lines
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "\n", " ", "\r", "")
+
+	for _, name := range sortedKeys(labels) {
+		lines = append(lines, name+"=\""+escaper.Replace(labels[name])+"\"")
+	}
+
+	return "LABEL " + strings.Join(lines, " \\\n      ") + "\n"
+}
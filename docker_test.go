@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerFormats(t *testing.T) {
+	a := require.New(t)
+	bi := &BuildInfo{
+		Version:          "1.2.3",
+		GitCommitHash:    "f96a75638b0e1767f969e23f383f4bc75c0e6ba0",
+		GitRef:           "fix it",
+		GitRepositoryURL: "https://github.com/fclairamb/ci-info",
+		BuildDate:        "2022-04-23T23:52:13Z",
+	}
+
+	labels, err := dockerValues(bi, ociLabels, map[string]string{
+		"org.opencontainers.image.title": "",
+		"com.example.branch":             "GitBranch",
+		"com.example.ref":                "GitRef",
+	})
+	a.NoError(err)
+	a.Equal(map[string]string{
+		"com.example.ref":                   "fix it",
+		"org.opencontainers.image.created":  "2022-04-23T23:52:13Z",
+		"org.opencontainers.image.version":  "1.2.3",
+		"org.opencontainers.image.revision": "f96a75638b0e1767f969e23f383f4bc75c0e6ba0",
+		"org.opencontainers.image.source":   "https://github.com/fclairamb/ci-info",
+		"org.opencontainers.image.ref.name": "fix it",
+	}, labels)
+
+	_, err = dockerValues(bi, map[string]string{"VERSION": "Verison"}, nil)
+	a.ErrorIs(err, errUnknownField)
+
+	labels = map[string]string{"org.opencontainers.image.version": "1.2.3", "com.example.ref": `it's "${HOME}" $1`}
+	buildArgs := map[string]string{"VERSION": "1.2.3"}
+
+	a.Equal(
+		`--label 'com.example.ref=it'\''s "${HOME}" $1' --label org.opencontainers.image.version=1.2.3 `+
+			"--build-arg VERSION=1.2.3\n",
+		dockerArgs(labels, buildArgs),
+	)
+
+	// The shell reads back the exact arguments, which are given like a command substitution would
+	labels["com.example.tricky"] = trickyShellValue
+	cmd := exec.Command("sh", "-c", `eval "set -- $ARGS"; printf '%s\0' "$@"`)
+	cmd.Env = append(os.Environ(), "ARGS="+dockerArgs(labels, buildArgs))
+	output, err := cmd.Output()
+	a.NoError(err)
+	a.Equal([]string{
+		"--label", `com.example.ref=it's "${HOME}" $1`,
+		"--label", "com.example.tricky=" + trickyShellValue,
+		"--label", "org.opencontainers.image.version=1.2.3",
+		"--build-arg", "VERSION=1.2.3",
+		"",
+	}, strings.Split(string(output), "\x00"))
+	delete(labels, "com.example.tricky")
+
+	a.Equal(`target "ci-info" {
+  labels = {
+    "com.example.ref" = "it's \"$${HOME}\" $1"
+    "org.opencontainers.image.version" = "1.2.3"
+  }
+  args = {
+    "VERSION" = "1.2.3"
+  }
+}
+`, dockerBakeHCL("ci-info", labels, buildArgs))
+
+	a.Equal(`LABEL com.example.ref="it's \"\${HOME}\" \$1" \
+      org.opencontainers.image.version="1.2.3"
+`, dockerfileLabels(labels))
+
+	content, err := dockerBakeJSON("app", labels, nil)
+	a.NoError(err)
+
+	var bake struct {
+		Target map[string]struct {
+			Labels map[string]string `json:"labels"`
+			Args   map[string]string `json:"args"`
+		} `json:"target"`
+	}
+	a.NoError(json.Unmarshal([]byte(content), &bake))
+	a.Equal(labels, bake.Target["app"].Labels)
+	a.Nil(bake.Target["app"].Args)
+}
+
+func TestMainDocker(t *testing.T) {
+	a := require.New(t)
+	dir := versionTestdata(t, "1.2.3")
+	configFile := filepath.Join(dir, ".ci-info.json")
+
+	output, err := captureStdout(t, func() error { return runMain([]string{"-c", configFile, "docker"}) })
+	a.NoError(err)
+	a.Contains(output, "--label org.opencontainers.image.version=1.2.3 ")
+	a.Contains(output, "--build-arg VERSION=1.2.3")
+
+	output, err = captureStdout(t, func() error {
+		return runMain([]string{"-c", configFile, "docker", "bake", "docker-bake.ci-info.hcl"})
+	})
+	a.NoError(err)
+	a.Empty(output)
+
+	content, err := os.ReadFile(filepath.Join(dir, "docker-bake.ci-info.hcl")) //nolint:gosec
+	a.NoError(err)
+	a.Contains(string(content), "\"org.opencontainers.image.version\" = \"1.2.3\"\n")
+
+	_, err = captureStdout(t, func() error { return runMain([]string{"-c", configFile, "docker", "compose"}) })
+	a.ErrorIs(err, errUnknownDockerFormat)
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"unicode"
+)
+
+var errUnknownLanguage = errors.New("unknown template language")
+
+const rawFunc = "raw"
+
+// escapers are the functions escaping a value to be put in a string literal of a language.
+// They don't add the quotes, except shellQuote which produces a complete shell word.
+var escapers = map[string]func(string) string{
+	"goString":     goString,
+	"cString":      cString,
+	"jsString":     jsString,
+	"pyString":     pyString,
+	"javaString":   javaString,
+	"kotlinString": kotlinString,
+	"csString":     csString,
+	"rustString":   rustString,
+	"swiftString":  swiftString,
+	"shellQuote":   shellQuote,
+	"rcString":     rcString,
+	"xmlEscape":    xmlEscape,
+	"jsonString":   jsonString,
+	"yamlString":   yamlString,
+}
+
+// languageEscapers defines the escaper applied to every interpolation of a template of a given language
+var languageEscapers = map[string]string{
+	"go":         "goString",
+	"c":          "cString",
+	"cpp":        "cString",
+	"javascript": "jsString",
+	"js":         "jsString",
+	"typescript": "jsString",
+	"ts":         "jsString",
+	"python":     "pyString",
+	"java":       "javaString",
+	"kotlin":     "kotlinString",
+	"csharp":     "csString",
+	"rust":       "rustString",
+	"swift":      "swiftString",
+	"shell":      "shellQuote",
+	"sh":         "shellQuote",
+	"rc":         "rcString",
+	"xml":        "xmlEscape",
+	"html":       "xmlEscape",
+	"plist":      "xmlEscape",
+	"json":       "jsonString",
+	"yaml":       "yamlString",
+}
+
+// escaperFuncs returns the escapers as template functions, accepting any value
+func escaperFuncs() template.FuncMap {
+	funcs := template.FuncMap{
+		rawFunc: func(value interface{}) string { return fmt.Sprint(value) },
+	}
+
+	for name, escaper := range escapers {
+		escaper := escaper
+		funcs[name] = func(value interface{}) string { return escaper(fmt.Sprint(value)) }
+	}
+
+	return funcs
+}
+
+// escapeTemplate appends the escaper of the language to every interpolation of the templates,
+// unless it already ends with an escaper or with "raw"
+func escapeTemplate(tpl *template.Template, language string) error {
+	escaper, ok := languageEscapers[strings.ToLower(language)]
+	if !ok {
+		return fmt.Errorf("%w: %s", errUnknownLanguage, language)
+	}
+
+	for _, t := range tpl.Templates() {
+		if t.Tree != nil && t.Tree.Root != nil {
+			escapeNode(t.Tree, t.Tree.Root, escaper)
+		}
+	}
+
+	return nil
+}
+
+func escapeNode(tree *parse.Tree, node parse.Node, escaper string) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+
+		for _, child := range n.Nodes {
+			escapeNode(tree, child, escaper)
+		}
+	case *parse.IfNode:
+		escapeNode(tree, n.List, escaper)
+		escapeNode(tree, n.ElseList, escaper)
+	case *parse.RangeNode:
+		escapeNode(tree, n.List, escaper)
+		escapeNode(tree, n.ElseList, escaper)
+	case *parse.WithNode:
+		escapeNode(tree, n.List, escaper)
+		escapeNode(tree, n.ElseList, escaper)
+	case *parse.ActionNode:
+		escapePipe(tree, n.Pipe, escaper)
+	}
+}
+
+func escapePipe(tree *parse.Tree, pipe *parse.PipeNode, escaper string) {
+	// Variable declarations don't output anything
+	if pipe == nil || len(pipe.Decl) > 0 || len(pipe.Cmds) == 0 {
+		return
+	}
+
+	last := pipe.Cmds[len(pipe.Cmds)-1]
+	if ident, ok := last.Args[0].(*parse.IdentifierNode); ok {
+		if _, isEscaper := escapers[ident.Ident]; isEscaper || ident.Ident == rawFunc {
+			return
+		}
+	}
+
+	ident := parse.NewIdentifier(escaper).SetTree(tree).SetPos(pipe.Pos)
+	pipe.Cmds = append(pipe.Cmds, &parse.CommandNode{
+		NodeType: parse.NodeCommand,
+		Pos:      pipe.Pos,
+		Args:     []parse.Node{ident},
+	})
+}
+
+// escapeWith escapes the quotes and backslashes, and calls special for the other runes.
+// special returns the escaped rune, or an empty string if the rune can be kept as is.
+func escapeWith(s string, quotes string, special func(r rune) string) string {
+	var b strings.Builder
+
+	for _, r := range s {
+		switch {
+		case r == '\\' || strings.ContainsRune(quotes, r):
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case special(r) != "":
+			b.WriteString(special(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// commonEscapes are the escape sequences shared by most of the C-like languages
+func commonEscapes(r rune) string {
+	switch r {
+	case '\n':
+		return `\n`
+	case '\r':
+		return `\r`
+	case '\t':
+		return `\t`
+	}
+
+	return ""
+}
+
+func goString(s string) string {
+	quoted := strconv.Quote(s)
+
+	return quoted[1 : len(quoted)-1]
+}
+
+func cString(s string) string {
+	escaped := escapeWith(s, `"'`, func(r rune) string {
+		if e := commonEscapes(r); e != "" {
+			return e
+		}
+
+		// Octal escapes don't consume the following characters like hexadecimal ones do
+		if r < 0x20 || r == 0x7f {
+			return fmt.Sprintf(`\%03o`, r)
+		}
+
+		return ""
+	})
+
+	// Prevents trigraphs
+	return strings.ReplaceAll(escaped, "??", `?\?`)
+}
+
+func jsString(s string) string {
+	return escapeWith(s, "\"'`", func(r rune) string {
+		switch {
+		case commonEscapes(r) != "":
+			return commonEscapes(r)
+		case r == '$':
+			return `\$`
+		case r < 0x20 || r == 0x7f || r == '\u2028' || r == '\u2029':
+			return fmt.Sprintf(`\u%04x`, r)
+		}
+
+		return ""
+	})
+}
+
+func pyString(s string) string {
+	return escapeWith(s, `"'`, func(r rune) string {
+		switch {
+		case commonEscapes(r) != "":
+			return commonEscapes(r)
+		case r < 0x20 || r == 0x7f:
+			return fmt.Sprintf(`\x%02x`, r)
+		}
+
+		return ""
+	})
+}
+
+func javaLikeEscapes(r rune) string {
+	switch {
+	case commonEscapes(r) != "":
+		return commonEscapes(r)
+	case r < 0x20 || r == 0x7f:
+		return fmt.Sprintf(`\u%04x`, r)
+	}
+
+	return ""
+}
+
+func javaString(s string) string {
+	return escapeWith(s, `"'`, javaLikeEscapes)
+}
+
+func kotlinString(s string) string {
+	// "$" starts string templates in kotlin
+	return escapeWith(s, `"'$`, javaLikeEscapes)
+}
+
+func csString(s string) string {
+	return escapeWith(s, `"'`, javaLikeEscapes)
+}
+
+func rustLikeEscapes(r rune) string {
+	switch {
+	case commonEscapes(r) != "":
+		return commonEscapes(r)
+	case r == 0:
+		return `\0`
+	case r < 0x20 || r == 0x7f:
+		return fmt.Sprintf(`\u{%x}`, r)
+	}
+
+	return ""
+}
+
+func rustString(s string) string {
+	return escapeWith(s, `"'`, rustLikeEscapes)
+}
+
+func swiftString(s string) string {
+	// Escaping the backslashes prevents "\(...)" interpolations
+	return escapeWith(s, `"'`, rustLikeEscapes)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// rcString escapes a value for the strings of the Windows resource scripts, in which quotes are doubled
+func rcString(s string) string {
+	return strings.ReplaceAll(escapeWith(s, "", func(r rune) string {
+		switch {
+		case commonEscapes(r) != "":
+			return commonEscapes(r)
+		case r < 0x20 || r == 0x7f:
+			return fmt.Sprintf(`\x%02x`, r)
+		}
+
+		return ""
+	}), `"`, `""`)
+}
+
+func xmlEscape(s string) string {
+	var b bytes.Buffer
+
+	// The invalid XML characters are replaced by the replacement character
+	_ = xml.EscapeText(&b, []byte(strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			return unicode.ReplacementChar
+		}
+
+		return r
+	}, s)))
+
+	return b.String()
+}
+
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+
+	return string(b[1 : len(b)-1])
+}
+
+func yamlString(s string) string {
+	// The go escape sequences are all supported by the YAML double-quoted style
+	return goString(s)
+}
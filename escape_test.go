@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapers(t *testing.T) {
+	const tricky = "a\"b\\c'd$e\nf\x01??/"
+
+	for _, test := range []struct {
+		name     string
+		expected string
+	}{
+		{"goString", `a\"b\\c'd$e\nf\x01??/`},
+		{"cString", `a\"b\\c\'d$e\nf\001?\?/`},
+		{"jsString", `a\"b\\c\'d\$e\nf\u0001??/`},
+		{"pyString", `a\"b\\c\'d$e\nf\x01??/`},
+		{"javaString", `a\"b\\c\'d$e\nf\u0001??/`},
+		{"kotlinString", `a\"b\\c\'d\$e\nf\u0001??/`},
+		{"csString", `a\"b\\c\'d$e\nf\u0001??/`},
+		{"rustString", `a\"b\\c\'d$e\nf\u{1}??/`},
+		{"swiftString", `a\"b\\c\'d$e\nf\u{1}??/`},
+		{"shellQuote", "'a\"b\\c'\\''d$e\nf\x01??/'"},
+		{"rcString", `a""b\\c'd$e\nf\x01??/`},
+		{"xmlEscape", "a&#34;b\\c&#39;d$e&#xA;f\uFFFD??/"},
+		{"jsonString", `a\"b\\c'd$e\nf\u0001??/`},
+		{"yamlString", `a\"b\\c'd$e\nf\x01??/`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, escapers[test.name](tricky))
+		})
+	}
+}
+
+func TestTemplateLanguage(t *testing.T) {
+	bi := &BuildInfo{
+		Version:   "1.2.3",
+		GitBranch: `fix/"quotes"`,
+	}
+
+	for _, test := range []struct {
+		language string
+		template string
+		expected string
+	}{
+		{"go", `const Branch = "{{ .GitBranch }}"`, `const Branch = "fix/\"quotes\""`},
+		{"go", `{{ if .Version }}"{{ .GitBranch | upper }}"{{ end }}`, `"FIX/\"QUOTES\""`},
+		{"go", `{{ range split "/" .GitBranch }}"{{ . }}",{{ end }}`, `"fix","\"quotes\"",`},
+		{"go", `{{ $b := .GitBranch }}"{{ $b }}"`, `"fix/\"quotes\""`},
+		{"go", `{{ .GitBranch | raw }}`, `fix/"quotes"`},
+		{"go", `"{{ .GitBranch | jsonString }}"`, `"fix/\"quotes\""`},
+		{"shell", `BRANCH={{ .GitBranch }}`, `BRANCH='fix/"quotes"'`},
+		{"xml", `<branch>{{ .GitBranch }}</branch>`, `<branch>fix/&#34;quotes&#34;</branch>`},
+		{"", `"{{ .GitBranch }}"`, `"fix/"quotes""`},
+	} {
+		t.Run(test.language+" "+test.template, func(t *testing.T) {
+			a := require.New(t)
+			content, err := (&ConfigTemplate{Language: test.language}).render(&Config{}, test.template, bi)
+			a.NoError(err)
+			a.Equal(test.expected, content)
+		})
+	}
+
+	_, err := (&ConfigTemplate{Language: "cobol"}).render(&Config{}, "{{ .Version }}", bi)
+	require.ErrorIs(t, err, errUnknownLanguage)
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"net/url"
 	"os"
 	"path"
+	"regexp"
+	"strings"
 	"time"
 
 	git "github.com/go-git/go-git/v5"
@@ -54,7 +57,8 @@ func getHead() (*git.Repository, *plumbing.Reference, error) {
 
 func fetchGitInfo(info *BuildInfo) error { //nolint:gocyclo
 	// If we have everything, we don't need to fetch anything
-	if info.GitCommitHash != "" && info.GitCommitDate != "" && (info.GitBranch != "" || info.GitTag != "") {
+	if info.GitCommitHash != "" && info.GitCommitDate != "" && (info.GitBranch != "" || info.GitTag != "") &&
+		info.GitRepositoryURL != "" {
 		return nil
 	}
 
@@ -100,6 +104,10 @@ func fetchGitInfo(info *BuildInfo) error { //nolint:gocyclo
 		}
 	}
 
+	if info.GitRepositoryURL == "" {
+		info.GitRepositoryURL = getRemoteURL(repo)
+	}
+
 	if info.GitLastTag == "" {
 		lastTag, err := getLastTagFromRepository(repo)
 
@@ -153,3 +161,115 @@ func getLastTagFromRepository(repository *git.Repository) (string, error) {
 
 	return latestTagName, nil
 }
+
+// getRemoteURL returns the URL of the "origin" remote, or of the first one
+func getRemoteURL(repo *git.Repository) string {
+	remotes, err := repo.Remotes()
+	if err != nil || len(remotes) == 0 {
+		return ""
+	}
+
+	remote := remotes[0]
+
+	for _, r := range remotes {
+		if r.Config().Name == git.DefaultRemoteName {
+			remote = r
+		}
+	}
+
+	if urls := remote.Config().URLs; len(urls) > 0 {
+		return urls[0]
+	}
+
+	return ""
+}
+
+var reSCPLikeURL = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// normalizeRepositoryURL returns the browsable HTTPS URL of a repository, without credentials:
+// git@github.com:fclairamb/ci-info.git becomes https://github.com/fclairamb/ci-info
+func normalizeRepositoryURL(repoURL string) string {
+	repoURL = strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(repoURL), "/"), ".git")
+
+	if !strings.Contains(repoURL, "://") {
+		if matches := reSCPLikeURL.FindStringSubmatch(repoURL); matches != nil {
+			return "https://" + matches[1] + "/" + strings.TrimPrefix(matches[2], "/")
+		}
+
+		return repoURL
+	}
+
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return repoURL
+	}
+
+	if u.Scheme == "ssh" || u.Scheme == "git" || u.Scheme == "git+ssh" {
+		u.Scheme = "https"
+		u.Host = u.Hostname()
+	}
+
+	u.User = nil
+
+	return u.String()
+}
+
+// maxCommitSubjects limits the commits listed when no tag is found
+const maxCommitSubjects = 100
+
+// getCommitSubjectsSinceLastTag returns the subjects of the commits since the last tag, the merge commits being
+// skipped. The tag of the current commit isn't considered, so that a release lists the commits since the previous one.
+func getCommitSubjectsSinceLastTag(dir string) ([]string, error) {
+	repo, err := getRepo(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	taggedCommits := map[plumbing.Hash]bool{}
+
+	tags, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tags.ForEach(func(tag *plumbing.Reference) error {
+		if hash, errResolve := repo.ResolveRevision(plumbing.Revision(tag.Name().String())); errResolve == nil {
+			taggedCommits[*hash] = true
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	commits, err := repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return nil, err
+	}
+
+	var subjects []string
+
+	err = commits.ForEach(func(commit *object.Commit) error {
+		if commit.Hash != head.Hash() && taggedCommits[commit.Hash] || len(subjects) >= maxCommitSubjects {
+			return storer.ErrStop
+		}
+
+		if commit.NumParents() <= 1 {
+			subjects = append(subjects, strings.TrimSpace(strings.SplitN(commit.Message, "\n", 2)[0]))
+		}
+
+		return nil
+	})
+
+	// The history of shallow clones is incomplete
+	if err != nil {
+		log.Warn("Could not list all the commits since the last tag", "err", err)
+	}
+
+	return subjects, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// globSkippedDirs are never walked into when expanding a "**" pattern
+var globSkippedDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+}
+
+// expandGlob returns the paths within dir matching pattern, relative to dir and using slashes.
+// On top of the filepath.Match syntax, a "**" path element matches any number of directories.
+func expandGlob(dir, pattern string) ([]string, error) {
+	pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+
+	if !strings.Contains(pattern, "**") {
+		matches, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, err
+		}
+
+		return relativePaths(dir, matches)
+	}
+
+	patternParts := strings.Split(pattern, "/")
+
+	// We only walk from the part of the pattern that doesn't contain any wildcard
+	var matches []string
+
+	root := filepath.Join(dir, filepath.FromSlash(globBase(pattern)))
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return fs.SkipDir
+			}
+
+			return err
+		}
+
+		if entry.IsDir() && path != root && globSkippedDirs[entry.Name()] {
+			return fs.SkipDir
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		if matchGlobParts(patternParts, strings.Split(filepath.ToSlash(rel), "/")) {
+			matches = append(matches, path)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return relativePaths(dir, matches)
+}
+
+// globBase returns the leading directories of a pattern that don't contain any wildcard
+func globBase(pattern string) string {
+	var staticParts []string
+
+	parts := strings.Split(strings.TrimPrefix(filepath.ToSlash(pattern), "./"), "/")
+
+	for _, part := range parts[:len(parts)-1] {
+		if strings.ContainsAny(part, "*?[") {
+			break
+		}
+
+		staticParts = append(staticParts, part)
+	}
+
+	return strings.Join(staticParts, "/")
+}
+
+func relativePaths(dir string, paths []string) ([]string, error) {
+	result := make([]string, 0, len(paths))
+
+	for _, path := range paths {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, filepath.ToSlash(rel))
+	}
+
+	sort.Strings(result)
+
+	return result, nil
+}
+
+func matchGlobParts(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchGlobParts(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(parts) == 0 {
+		return false
+	}
+
+	if ok, err := filepath.Match(pattern[0], parts[0]); err != nil || !ok {
+		return false
+	}
+
+	return matchGlobParts(pattern[1:], parts[1:])
+}
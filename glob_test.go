@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandGlob(t *testing.T) {
+	a := require.New(t)
+
+	matches, err := expandGlob("testdata/pnpm", "packages/*")
+	a.NoError(err)
+	a.Equal([]string{"packages/api", "packages/web"}, matches)
+
+	matches, err = expandGlob("testdata/pnpm", "**/package.json")
+	a.NoError(err)
+	a.Equal([]string{
+		"package.json",
+		"packages/api/package.json",
+		"packages/web/package.json",
+		"tools/legacy/package.json",
+	}, matches)
+
+	matches, err = expandGlob("testdata/pnpm", "not-here/**")
+	a.NoError(err)
+	a.Empty(matches)
+}
+
+func TestGlobBase(t *testing.T) {
+	a := require.New(t)
+
+	a.Equal("templates", globBase("templates/**/*.tpl"))
+	a.Equal("a/b", globBase("./a/b/*.tpl"))
+	a.Equal("", globBase("*.tpl"))
+	a.Equal("", globBase("**/version.tpl"))
+}
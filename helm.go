@@ -0,0 +1,404 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	errYAMLPathNotFound      = errors.New("yaml path not found")
+	errYAMLNotScalar         = errors.New("yaml value is not a scalar")
+	errYAMLStyleNotSupported = errors.New("yaml value style not supported")
+	errInvalidYAMLPath       = errors.New("invalid yaml path")
+)
+
+const helmChartFile = "Chart.yaml"
+
+// helmInfoFetcher is a fetcher for Helm charts
+type helmInfoFetcher struct{}
+
+func (f helmInfoFetcher) Detect(dir string) bool {
+	st, err := os.Stat(path.Join(dir, helmChartFile))
+	if err != nil {
+		return false
+	}
+
+	return !st.IsDir()
+}
+
+// Fetch parses a Chart.yaml file and retrieve the version and appVersion properties
+func (f helmInfoFetcher) Fetch(dir string, bi *BuildInfo) error {
+	b, err := os.ReadFile(path.Join(dir, helmChartFile)) //nolint:gosec
+	if err != nil {
+		return err
+	}
+
+	var chart struct {
+		Name       string `yaml:"name"`
+		Version    string `yaml:"version"`
+		AppVersion string `yaml:"appVersion"`
+	}
+
+	if err := yaml.Unmarshal(b, &chart); err != nil {
+		return fmt.Errorf("could not parse %s: %w", helmChartFile, err)
+	}
+
+	bi.PackageName = chart.Name
+	bi.PackageVersion = chart.Version
+	bi.ChartVersion = chart.Version
+	bi.ChartAppVersion = chart.AppVersion
+	bi.VersionDeclared = chart.Version
+
+	return nil
+}
+
+func (f helmInfoFetcher) String() string {
+	return "helm"
+}
+
+// saveHelmFiles writes the version as the appVersion of the chart and optionally as the image tag of the values
+func saveHelmFiles(config *Config, buildInfo *BuildInfo) error {
+	helm := config.Helm
+	chartDir := path.Join(config.Directory, helm.Chart)
+	chartFile := path.Join(chartDir, helmChartFile)
+
+	// appVersion is optional, it's added after the version of the chart if it's missing
+	err := setYAMLFileValue(config.writer(), chartFile, "appVersion", buildInfo.Version)
+	if errors.Is(err, errYAMLPathNotFound) {
+		err = addHelmAppVersion(config.writer(), chartFile, buildInfo.Version)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if helm.ImageTag != "" {
+		valuesFile := helm.ValuesFile
+		if valuesFile == "" {
+			valuesFile = "values.yaml"
+		}
+
+		if err := setYAMLFileValue(config.writer(), path.Join(chartDir, valuesFile), helm.ImageTag, buildInfo.Version); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addHelmAppVersion adds the appVersion to a chart that doesn't have one, on the line following its version
+func addHelmAppVersion(writer outputWriter, fileName, appVersion string) error {
+	content, err := writer.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(content, doc); err != nil {
+		return fmt.Errorf("could not parse %s: %w", fileName, err)
+	}
+
+	offset := len(content)
+	if node, _ := findYAMLNode(doc, []string{"version"}, false); node != nil {
+		if lineOffsets := getLineOffsets(content); node.Line < len(lineOffsets) {
+			offset = lineOffsets[node.Line]
+		}
+	}
+
+	line := "appVersion: " + yamlToken(appVersion, yaml.DoubleQuotedStyle, false) + "\n"
+	if offset > 0 && content[offset-1] != '\n' {
+		line = "\n" + line
+	}
+
+	log.Debug("Adding the appVersion to the chart", "path", fileName)
+
+	return writer.WriteFile(fileName, append(append(append([]byte{}, content[:offset]...), line...), content[offset:]...), 0)
+}
+
+// saveYAMLValues writes the rendered values at their path in YAML files (typically kubernetes manifests)
+func saveYAMLValues(config *Config, buildInfo *BuildInfo) error {
+	for _, yamlValue := range config.YAMLValues {
+		content := yamlValue.Value
+		if content == "" {
+			content = "{{ .Version }}"
+		}
+
+		value, err := renderTemplate(content, buildInfo)
+		if err != nil {
+			return fmt.Errorf("failed to render value for %s: %w", yamlValue.Path, err)
+		}
+
+		if err := setYAMLFileValue(config.writer(), path.Join(config.Directory, yamlValue.File), yamlValue.Path, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func setYAMLFileValue(writer outputWriter, fileName, yamlPath, value string) error {
+	content, err := writer.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	updated, err := setYAMLValue(content, yamlPath, value)
+	if err != nil {
+		return fmt.Errorf("could not set %s in %s: %w", yamlPath, fileName, err)
+	}
+
+	if bytes.Equal(content, updated) {
+		return nil
+	}
+
+	log.Debug("Updating YAML file", "path", fileName, "key", yamlPath)
+
+	return writer.WriteFile(fileName, updated, 0)
+}
+
+// setYAMLValue replaces the scalar at yamlPath (dot separated, with indexes for sequences) in all the
+// documents of content. Only the value is rewritten, so that formatting and comments are preserved.
+func setYAMLValue(content []byte, yamlPath, value string) ([]byte, error) {
+	keys, err := splitYAMLPath(yamlPath)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+
+	type target struct {
+		node *yaml.Node
+		flow bool
+	}
+
+	var targets []target
+
+	for {
+		doc := &yaml.Node{}
+		if err := decoder.Decode(doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, err
+		}
+
+		if node, flow := findYAMLNode(doc, keys, false); node != nil {
+			targets = append(targets, target{node, flow})
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, errYAMLPathNotFound
+	}
+
+	lineOffsets := getLineOffsets(content)
+
+	type replacement struct {
+		start, end int
+		token      string
+	}
+
+	replacements := make([]replacement, 0, len(targets))
+
+	for _, target := range targets {
+		node := target.node
+		if node.Kind != yaml.ScalarNode {
+			return nil, errYAMLNotScalar
+		}
+
+		lineStart := lineOffsets[node.Line-1]
+		start := lineStart + runesToBytes(content[lineStart:], node.Column-1)
+
+		end, err := yamlTokenEnd(content, start, node.Style, target.flow)
+		if err != nil {
+			return nil, err
+		}
+
+		// A plain scalar is written as is, so anything else (like a multi-line one) isn't the token we found
+		if node.Style == 0 && string(content[start:end]) != node.Value {
+			return nil, errYAMLStyleNotSupported
+		}
+
+		replacements = append(replacements, replacement{start, end, yamlToken(value, node.Style, target.flow)})
+	}
+
+	// We replace from the end so that the offsets stay valid
+	sort.Slice(replacements, func(i, j int) bool { return replacements[i].start > replacements[j].start })
+
+	result := content
+
+	for _, r := range replacements {
+		result = append(append(append([]byte{}, result[:r.start]...), r.token...), result[r.end:]...)
+	}
+
+	return result, nil
+}
+
+// splitYAMLPath returns the keys of a path like metadata.labels["app.kubernetes.io/version"], the keys containing
+// dots being quoted between brackets
+func splitYAMLPath(yamlPath string) ([]string, error) {
+	var keys []string
+
+	for rest := yamlPath; ; {
+		if strings.HasPrefix(rest, "[") {
+			if len(rest) < 2 || (rest[1] != '"' && rest[1] != '\'') {
+				return nil, fmt.Errorf("%w: %s", errInvalidYAMLPath, yamlPath)
+			}
+
+			end := strings.Index(rest[2:], string(rest[1])+"]")
+			if end < 0 {
+				return nil, fmt.Errorf("%w: %s", errInvalidYAMLPath, yamlPath)
+			}
+
+			keys = append(keys, rest[2:2+end])
+			rest = rest[2+end+2:]
+		} else {
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+
+			keys = append(keys, rest[:end])
+			rest = rest[end:]
+		}
+
+		if rest == "" {
+			return keys, nil
+		}
+
+		if rest[0] == '.' {
+			rest = rest[1:]
+		} else if rest[0] != '[' {
+			return nil, fmt.Errorf("%w: %s", errInvalidYAMLPath, yamlPath)
+		}
+	}
+}
+
+// findYAMLNode returns the node at the keys path, and if it's in a flow collection ({a: b} or [a, b])
+func findYAMLNode(node *yaml.Node, keys []string, flow bool) (*yaml.Node, bool) {
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil, false
+		}
+
+		return findYAMLNode(node.Content[0], keys, flow)
+	}
+
+	if len(keys) == 0 {
+		return node, flow
+	}
+
+	flow = flow || node.Style&yaml.FlowStyle != 0
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == keys[0] {
+				return findYAMLNode(node.Content[i+1], keys[1:], flow)
+			}
+		}
+	case yaml.SequenceNode:
+		if index, err := strconv.Atoi(keys[0]); err == nil && index >= 0 && index < len(node.Content) {
+			return findYAMLNode(node.Content[index], keys[1:], flow)
+		}
+	}
+
+	return nil, false
+}
+
+func getLineOffsets(content []byte) []int {
+	offsets := []int{0}
+
+	for i, c := range content {
+		if c == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+
+	return offsets
+}
+
+func runesToBytes(content []byte, runes int) int {
+	offset := 0
+
+	for i := 0; i < runes && offset < len(content); i++ {
+		_, size := utf8.DecodeRune(content[offset:])
+		offset += size
+	}
+
+	return offset
+}
+
+// yamlTokenEnd returns the offset right after the scalar token starting at start. In a flow collection, a plain
+// scalar also ends at the flow indicators.
+func yamlTokenEnd(content []byte, start int, style yaml.Style, flow bool) (int, error) {
+	switch style {
+	case yaml.DoubleQuotedStyle:
+		for i := start + 1; i < len(content); i++ {
+			switch content[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1, nil
+			}
+		}
+	case yaml.SingleQuotedStyle:
+		for i := start + 1; i < len(content); i++ {
+			if content[i] == '\'' {
+				if i+1 < len(content) && content[i+1] == '\'' {
+					i++
+
+					continue
+				}
+
+				return i + 1, nil
+			}
+		}
+	case 0:
+		end := start
+		for end < len(content) && content[end] != '\n' {
+			if content[end] == '#' && end > start && (content[end-1] == ' ' || content[end-1] == '\t') {
+				break
+			}
+
+			if flow && bytes.IndexByte([]byte(",[]{}"), content[end]) >= 0 {
+				break
+			}
+			end++
+		}
+
+		return start + len(bytes.TrimRight(content[start:end], " \t\r")), nil
+	}
+
+	return 0, errYAMLStyleNotSupported
+}
+
+// yamlToken formats the value in the same style as the one it replaces, unless it isn't safe to do so
+func yamlToken(value string, style yaml.Style, flow bool) string {
+	switch style {
+	case yaml.SingleQuotedStyle:
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	case yaml.DoubleQuotedStyle:
+		return strconv.Quote(value)
+	}
+
+	// A plain value is only kept plain if it's read back as the same string
+	var decoded interface{}
+	if err := yaml.Unmarshal([]byte(value), &decoded); err == nil && decoded == value && !strings.ContainsAny(value, "\n#") &&
+		(!flow || !strings.ContainsAny(value, ",[]{}")) {
+		return value
+	}
+
+	return strconv.Quote(value)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHelmFetcher(t *testing.T) {
+	a := require.New(t)
+	bi := &BuildInfo{}
+	a.NoError(fetchPackageManagerInfo("testdata/helm/app", bi))
+	a.Equal("helm", bi.PackageManager)
+	a.Equal("0.3.1", bi.VersionDeclared)
+	a.Equal("0.3.1", bi.ChartVersion)
+	a.Equal("1.16.0", bi.ChartAppVersion)
+}
+
+func TestSetYAMLValue(t *testing.T) {
+	a := require.New(t)
+
+	content, err := os.ReadFile("testdata/helm/app/Chart.yaml")
+	a.NoError(err)
+
+	updated, err := setYAMLValue(content, "appVersion", "1.2.3-main-a6850c9")
+	a.NoError(err)
+	a.Contains(string(updated), "\nappVersion: \"1.2.3-main-a6850c9\" # replaced by ci-info\n")
+	a.Contains(string(updated), "# This is the chart version.")
+	a.Equal(len(content)-len("1.16.0")+len("1.2.3-main-a6850c9"), len(updated))
+
+	again, err := setYAMLValue(updated, "appVersion", "1.2.3-main-a6850c9")
+	a.NoError(err)
+	a.Equal(updated, again)
+
+	_, err = setYAMLValue(content, "notHere", "1.2.3")
+	a.ErrorIs(err, errYAMLPathNotFound)
+}
+
+func TestSetYAMLValueManifests(t *testing.T) {
+	a := require.New(t)
+
+	content, err := os.ReadFile("testdata/helm/k8s/deployment.yaml")
+	a.NoError(err)
+
+	updated, err := setYAMLValue(content, "spec.template.spec.containers.0.image", "ghcr.io/acme/app:1.2.3")
+	a.NoError(err)
+	a.Contains(string(updated), "          image: ghcr.io/acme/app:1.2.3\n")
+
+	// All the documents having the path are updated
+	updated, err = setYAMLValue(content, "metadata.name", "renamed")
+	a.NoError(err)
+	a.Equal(2, strings.Count(string(updated), "  name: renamed\n"))
+
+	// Plain values that would be read as numbers get quoted
+	updated, err = setYAMLValue(content, "data.version", "1.10")
+	a.NoError(err)
+	a.Contains(string(updated), "  version: \"1.10\" # current version\n")
+
+	// The keys containing dots are quoted
+	updated, err = setYAMLValue(content, `metadata.labels["app.kubernetes.io/version"]`, "1.2.3")
+	a.NoError(err)
+	a.Contains(string(updated), "    app.kubernetes.io/version: '1.2.3'\n")
+
+	updated, err = setYAMLValue(content, `metadata['labels']["app.kubernetes.io/version"]`, "1.2.4")
+	a.NoError(err)
+	a.Contains(string(updated), "    app.kubernetes.io/version: '1.2.4'\n")
+
+	for _, invalid := range []string{`metadata.labels[app]`, `metadata.labels["app`, `metadata["labels"]name`} {
+		_, err = setYAMLValue(content, invalid, "1.2.3")
+		a.ErrorIs(err, errInvalidYAMLPath, invalid)
+	}
+}
+
+func TestSetYAMLValueFlow(t *testing.T) {
+	a := require.New(t)
+
+	content := []byte("image: {repository: x, tag: 1.0}\nversions: [1.0, 2.0] # supported\n")
+
+	updated, err := setYAMLValue(content, "image.tag", "1.2.3")
+	a.NoError(err)
+	a.Equal("image: {repository: x, tag: 1.2.3}\nversions: [1.0, 2.0] # supported\n", string(updated))
+
+	updated, err = setYAMLValue(content, "versions.0", "1.2.3")
+	a.NoError(err)
+	a.Equal("image: {repository: x, tag: 1.0}\nversions: [1.2.3, 2.0] # supported\n", string(updated))
+
+	// The flow indicators can't be written in a plain value
+	updated, err = setYAMLValue(content, "versions.1", "1.2,3")
+	a.NoError(err)
+	a.Equal("image: {repository: x, tag: 1.0}\nversions: [1.0, \"1.2,3\"] # supported\n", string(updated))
+
+	// The multi-line plain values can't be replaced
+	_, err = setYAMLValue([]byte("image: {tag: one\n  two}\n"), "image.tag", "1.2.3")
+	a.ErrorIs(err, errYAMLStyleNotSupported)
+}
+
+func TestMainRunHelm(t *testing.T) {
+	a := require.New(t)
+
+	dir := copyTestdata(t, "testdata/helm/app")
+
+	a.NoError(runMain([]string{"-c", path.Join(dir, ".ci-info.json")}))
+
+	chart, err := os.ReadFile(path.Join(dir, "Chart.yaml"))
+	a.NoError(err)
+	a.Regexp(`\nappVersion: "0\.3\.1-[^"]+" # replaced by ci-info\n`, string(chart))
+
+	values, err := os.ReadFile(path.Join(dir, "values.yaml"))
+	a.NoError(err)
+	a.Regexp(`\n  # Overrides the image tag whose default is the chart appVersion.\n  tag: "0\.3\.1-[^"]+"\n`, string(values))
+}
+
+func TestMainRunHelmWithoutAppVersion(t *testing.T) {
+	a := require.New(t)
+
+	dir := copyTestdata(t, "testdata/helm/app")
+	chartFile := path.Join(dir, "Chart.yaml")
+
+	a.NoError(os.WriteFile(chartFile, []byte("apiVersion: v2\nname: app\nversion: 0.3.1 # chart\ntype: application"), 0600))
+	a.NoError(runMain([]string{"-c", path.Join(dir, ".ci-info.json")}))
+
+	chart, err := os.ReadFile(chartFile) //nolint:gosec
+	a.NoError(err)
+	a.Regexp(`^apiVersion: v2\nname: app\nversion: 0\.3\.1 # chart\nappVersion: "0\.3\.1-[^"]+"\ntype: application$`, string(chart))
+
+	// The key is added only once
+	a.NoError(runMain([]string{"-c", path.Join(dir, ".ci-info.json")}))
+
+	again, err := os.ReadFile(chartFile) //nolint:gosec
+	a.NoError(err)
+	a.Equal(1, strings.Count(string(again), "appVersion:"))
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	errLDFlagsQuoting       = errors.New("value can't be quoted for ldflags, it contains both single and double quotes")
+	errUnknownLDFlagsFormat = errors.New("unknown ldflags format")
+	errNoModulePath         = errors.New("no module path")
+)
+
+const ldflagsFormatGoreleaser = "goreleaser"
+
+// defaultLDFlagsSymbols are the variables set when no symbol is configured, the ones of the build.go template
+var defaultLDFlagsSymbols = map[string]string{
+	"BuildVersion": "Version",
+	"BuildDate":    "BuildDate",
+	"Commit":       "GitSmartRef",
+}
+
+var reGoModule = regexp.MustCompile(`(?m)^module\s+"?([^"\s]+)"?\s*$`)
+
+// runLDFlags prints the linker flags setting the build info in go variables: go build -ldflags "$(ci-info ldflags)"
+func runLDFlags(config *Config, args []string) error {
+	format := ""
+	if len(args) > 0 {
+		format = args[0]
+	}
+
+	if format != "" && format != ldflagsFormatGoreleaser {
+		return fmt.Errorf("%w: %s", errUnknownLDFlagsFormat, format)
+	}
+
+	buildInfo, err := generateBuildInfo(config)
+	if err != nil {
+		return err
+	}
+
+	flags, err := getLDFlags(config, buildInfo)
+	if err != nil {
+		return err
+	}
+
+	if format == ldflagsFormatGoreleaser {
+		// The ldflags of goreleaser are templates themselves
+		for _, flag := range flags {
+			fmt.Printf("- \"%s\"\n", yamlString(strings.ReplaceAll(flag, "{{", `{{ "{{" }}`)))
+		}
+
+		return nil
+	}
+
+	fmt.Println(strings.Join(flags, " "))
+
+	return nil
+}
+
+// getLDFlags returns a "-X" flag per symbol, the symbols being prefixed by the import path of the package
+func getLDFlags(config *Config, buildInfo *BuildInfo) ([]string, error) {
+	ldFlags := ConfigLDFlags{}
+	if config.LDFlags != nil {
+		ldFlags = *config.LDFlags
+	}
+
+	symbols := ldFlags.Symbols
+	if len(symbols) == 0 {
+		symbols = defaultLDFlagsSymbols
+	}
+
+	pkg, err := getLDFlagsPackage(config.Directory, ldFlags.Package)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := make([]string, 0, len(symbols))
+
+	for _, symbol := range sortedKeys(symbols) {
+		value, err := buildInfo.fieldValue(symbols[symbol])
+		if err != nil {
+			return nil, err
+		}
+
+		// A symbol can also be fully qualified
+		if !strings.Contains(symbol, ".") {
+			symbol = pkg + "." + symbol
+		}
+
+		quoted, err := quoteLDFlag(symbol + "=" + value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, symbol)
+		}
+
+		flags = append(flags, "-X "+quoted)
+	}
+
+	return flags, nil
+}
+
+// getLDFlagsPackage returns the import path of the package of the symbols, from the module path of go.mod
+func getLDFlagsPackage(dir, pkg string) (string, error) {
+	pkg = strings.Trim(pkg, "/")
+	if pkg == "" || pkg == "main" {
+		return "main", nil
+	}
+
+	modulePath, err := getGoModulePath(dir)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.HasPrefix(pkg, modulePath+"/") {
+		return pkg, nil
+	}
+
+	return modulePath + "/" + pkg, nil
+}
+
+// getGoModulePath returns the module path declared by the closest go.mod file
+func getGoModulePath(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		content, err := os.ReadFile(filepath.Join(dir, "go.mod")) //nolint:gosec
+		if err == nil {
+			if matches := reGoModule.FindSubmatch(content); matches != nil {
+				return string(matches[1]), nil
+			}
+
+			return "", fmt.Errorf("%w in %s", errNoModulePath, filepath.Join(dir, "go.mod"))
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("could not find go.mod: %w", os.ErrNotExist)
+		}
+
+		dir = parent
+	}
+}
+
+// quoteLDFlag quotes a value the way the go command splits the ldflags: quotes group characters, and
+// there is no escape character
+func quoteLDFlag(s string) (string, error) {
+	switch {
+	case s != "" && !strings.ContainsAny(s, " \t\n\r'\""):
+		return s, nil
+	case !strings.Contains(s, "'"):
+		return "'" + s + "'", nil
+	case !strings.Contains(s, `"`):
+		return `"` + s + `"`, nil
+	default:
+		return "", errLDFlagsQuoting
+	}
+}
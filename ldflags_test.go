@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteLDFlag(t *testing.T) {
+	a := require.New(t)
+
+	for input, expected := range map[string]string{
+		"main.Version=1.2.3":              "main.Version=1.2.3",
+		"main.Branch=fix it":              "'main.Branch=fix it'",
+		`main.Branch=say "hi" $HOME`:      `'main.Branch=say "hi" $HOME'`,
+		"main.Branch=it's":                `"main.Branch=it's"`,
+		"main.Empty=":                     "main.Empty=",
+		"main.Branch=`id` \\ backslashes": "'main.Branch=`id` \\ backslashes'",
+	} {
+		quoted, err := quoteLDFlag(input)
+		a.NoError(err)
+		a.Equal(expected, quoted)
+	}
+
+	_, err := quoteLDFlag(`main.Branch=it's "quoted"`)
+	a.ErrorIs(err, errLDFlagsQuoting)
+}
+
+func TestGetLDFlags(t *testing.T) {
+	a := require.New(t)
+	bi := &BuildInfo{Version: "1.2.3", BuildDate: "2022-04-23T23:52:13Z", GitSmartRef: "fix-it", GitBranch: "fix it"}
+
+	flags, err := getLDFlags(&Config{}, bi)
+	a.NoError(err)
+	a.Equal([]string{
+		"-X main.BuildDate=2022-04-23T23:52:13Z",
+		"-X main.BuildVersion=1.2.3",
+		"-X main.Commit=fix-it",
+	}, flags)
+
+	// The package is relative to the module path
+	config := &Config{LDFlags: &ConfigLDFlags{
+		Package: "internal/version",
+		Symbols: map[string]string{"Version": "version", "Branch": "GitBranch", "example.com/other.Ref": "GitSmartRef"},
+	}}
+
+	flags, err = getLDFlags(config, bi)
+	a.NoError(err)
+	a.Equal([]string{
+		"-X 'github.com/fclairamb/ci-info/internal/version.Branch=fix it'",
+		"-X github.com/fclairamb/ci-info/internal/version.Version=1.2.3",
+		"-X example.com/other.Ref=fix-it",
+	}, flags)
+
+	config.LDFlags.Symbols = map[string]string{"Version": "Verison"}
+	_, err = getLDFlags(config, bi)
+	a.ErrorIs(err, errUnknownField)
+}
+
+// TestLDFlagsBuild checks that the go linker sets the values as they are
+func TestLDFlagsBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a program")
+	}
+
+	a := require.New(t)
+	dir := t.TempDir()
+
+	a.NoError(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.19\n"), 0600))
+	a.NoError(os.WriteFile(filepath.Join(dir, "main.go"), []byte(
+		"package main\n\nvar Branch string\n\nfunc main() { print(Branch) }\n",
+	), 0600))
+
+	branch := `feature/it's a "test" with $HOME`
+	flags, err := getLDFlags(&Config{Directory: dir, LDFlags: &ConfigLDFlags{
+		Symbols: map[string]string{"Branch": "GitBranch"},
+	}}, &BuildInfo{GitBranch: strings.ReplaceAll(branch, `"`, "")})
+	a.NoError(err)
+
+	cmd := exec.Command("go", "run", "-ldflags", strings.Join(flags, " "), ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=")
+
+	output, err := cmd.CombinedOutput()
+	a.NoError(err, string(output))
+	a.Equal(strings.ReplaceAll(branch, `"`, ""), string(output))
+}
+
+func TestMainLDFlags(t *testing.T) {
+	a := require.New(t)
+	configFile := filepath.Join(versionTestdata(t, "1.2.3"), ".ci-info.json")
+
+	output, err := captureStdout(t, func() error { return runMain([]string{"-c", configFile, "ldflags"}) })
+	a.NoError(err)
+	a.Regexp(`^-X main.BuildDate=\S+ -X main.BuildVersion=1.2.3 -X main.Commit=\S+\n$`, output)
+
+	output, err = captureStdout(t, func() error { return runMain([]string{"-c", configFile, "ldflags", "goreleaser"}) })
+	a.NoError(err)
+	a.Contains(output, "- \"-X main.BuildVersion=1.2.3\"\n")
+
+	_, err = captureStdout(t, func() error { return runMain([]string{"-c", configFile, "ldflags", "bazel"}) })
+	a.ErrorIs(err, errUnknownLDFlagsFormat)
+}
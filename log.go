@@ -18,7 +18,7 @@ func SetLoggingLevel(level string) error {
 		return err
 	}
 
-	log.SetHandler(log15.LvlFilterHandler(lvl, log15.StdoutHandler))
+	log.SetHandler(log15.LvlFilterHandler(lvl, log15.StderrHandler))
 
 	return nil
 }
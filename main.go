@@ -2,13 +2,11 @@
 package main
 
 import (
-	"bytes"
 	"errors"
 	"flag"
 	"fmt"
 	"os"
-	"path"
-	"text/template"
+	"strings"
 )
 
 func generateBuildInfo(config *Config) (*BuildInfo, error) {
@@ -41,36 +39,78 @@ func generateBuildInfo(config *Config) (*BuildInfo, error) {
 		return nil, fmt.Errorf("failed to load version info: %w", err)
 	}
 
+	// The variables can use all the other information
+	if err := buildInfo.loadVariables(config); err != nil {
+		return nil, fmt.Errorf("failed to load variables: %w", err)
+	}
+
 	return buildInfo, nil
 }
 
 func saveOutputFiles(config *Config, buildInfo *BuildInfo) error {
 	// If requested, we export the build info to a json file
 	if config.BuildInfoFile != "" {
-		if err := buildInfo.save(path.Join(config.Directory, config.BuildInfoFile)); err != nil {
+		fileName := config.outputPath(config.BuildInfoFile)
+		format := buildInfoFormat{
+			Format:  config.BuildInfoFormat,
+			Fields:  config.BuildInfoFields,
+			KeyCase: config.BuildInfoKeyCase,
+		}
+
+		if err := buildInfo.save(config.writer(), fileName, format); err != nil {
 			return fmt.Errorf("failed to save build info: %w", err)
 		}
+
+		// If requested, we sign it
+		if config.Signing != nil && config.Signing.Key != "" {
+			if err := signBuildInfo(config, buildInfo, fileName, format); err != nil {
+				return fmt.Errorf("failed to sign build info: %w", err)
+			}
+		}
 	}
 
 	// If requested, we generate the build info file from a template
-	for _, template := range config.Templates {
-		if template.OutputFile != "" {
-			var templateString string
-
-			if template.InputContent != "" {
-				templateString = template.InputContent
-			} else {
-				log.Debug("Loading template", "path", template.InputFile)
-				content, _, err := loadPathAsContent(template.InputFile, config.Directory)
-				if err != nil {
-					return fmt.Errorf("failed to load template file from %s: %w", template.InputFile, err)
-				}
-				templateString = string(content)
-			}
+	if err := saveTemplates(config, buildInfo); err != nil {
+		return err
+	}
 
-			if err := applyTemplate(templateString, path.Join(config.Directory, template.OutputFile), buildInfo); err != nil {
-				return fmt.Errorf("failed to apply template for %s: %w", template.OutputFile, err)
-			}
+	// If requested, we update the helm chart
+	if config.Helm != nil {
+		if err := saveHelmFiles(config, buildInfo); err != nil {
+			return fmt.Errorf("failed to update helm chart: %w", err)
+		}
+	}
+
+	if err := saveYAMLValues(config, buildInfo); err != nil {
+		return fmt.Errorf("failed to update yaml values: %w", err)
+	}
+
+	if err := savePlists(config, buildInfo); err != nil {
+		return fmt.Errorf("failed to update plists: %w", err)
+	}
+
+	if config.Android != nil {
+		if err := saveAndroidFiles(config, buildInfo); err != nil {
+			return fmt.Errorf("failed to write android version: %w", err)
+		}
+	}
+
+	if config.Debian != nil {
+		if err := saveDebianChangelog(config, buildInfo); err != nil {
+			return fmt.Errorf("failed to update debian changelog: %w", err)
+		}
+	}
+
+	if config.RPM != nil {
+		if err := saveRPMSpecFiles(config, buildInfo); err != nil {
+			return fmt.Errorf("failed to update spec files: %w", err)
+		}
+	}
+
+	// If requested, we publish the build info to the CI
+	if config.CIExport != nil {
+		if err := exportCIOutputs(config, buildInfo); err != nil {
+			return fmt.Errorf("failed to export to CI: %w", err)
 		}
 	}
 
@@ -136,11 +176,71 @@ func processParams(params *CmdParams) (*Config, error) {
 		})
 	}
 
+	if params.Package != "" {
+		config.Package = params.Package
+	}
+
+	if config.ShellEnv == nil && (params.Shell != "" || params.Prefix != "" || params.Fields != "") {
+		config.ShellEnv = &ConfigShellEnv{}
+	}
+
+	if params.Shell != "" {
+		config.ShellEnv.Shell = params.Shell
+	}
+
+	if params.Prefix != "" {
+		config.ShellEnv.Prefix = &params.Prefix
+	}
+
+	if params.Fields != "" {
+		config.ShellEnv.Fields = strings.Split(params.Fields, ",")
+	}
+
+	if params.CIExport && config.CIExport == nil {
+		config.CIExport = &ConfigCIExport{}
+	}
+
+	if params.DryRun {
+		config.output = dryRunOutput{}
+	}
+
+	if params.CheckIgnore != "" {
+		if config.Check == nil {
+			config.Check = &ConfigCheck{}
+		}
+
+		config.Check.Ignore = append(config.Check.Ignore, strings.Split(params.CheckIgnore, ",")...)
+	}
+
+	if params.Key != "" {
+		if config.Signing == nil {
+			config.Signing = &ConfigSigning{}
+		}
+
+		if params.Command == cmdVerify {
+			config.Signing.PublicKey = params.Key
+		} else {
+			config.Signing.Key = params.Key
+		}
+	}
+
+	for key, value := range params.Variables {
+		if config.Variables == nil {
+			config.Variables = map[string]string{}
+		}
+
+		config.Variables[key] = value
+	}
+
 	// If specified, we create the build info file
 	if params.OutputBuildInfoFile != "" {
 		config.BuildInfoFile = params.OutputBuildInfoFile
 	}
 
+	if params.BuildInfoFormat != "" {
+		config.BuildInfoFormat = params.BuildInfoFormat
+	}
+
 	log.Debug("Loaded config", "config", config)
 
 	return config, nil
@@ -167,6 +267,10 @@ func runMain(args []string) error {
 		return fmt.Errorf("failed to process params: %w", err)
 	}
 
+	if params.Command != "" {
+		return runCommand(params, config)
+	}
+
 	var buildInfo *BuildInfo
 
 	if buildInfo, err = generateBuildInfo(config); err != nil {
@@ -184,23 +288,3 @@ func runMain(args []string) error {
 
 	return nil
 }
-
-func applyTemplate(templateString string, outputFile string, buildInfo *BuildInfo) error {
-	var buffer bytes.Buffer
-
-	if tpl, err := template.New("").Parse(templateString); err == nil {
-		if errExec := tpl.Execute(&buffer, buildInfo); errExec != nil {
-			return fmt.Errorf("could not execute template: %w", err)
-		}
-	} else {
-		return fmt.Errorf("could not parse template: %w", err)
-	}
-
-	log.Debug("Saving formatted file", "path", outputFile)
-
-	if err := os.WriteFile(outputFile, buffer.Bytes(), 0644); err != nil { //nolint:gosec
-		return fmt.Errorf("could not write output file: %w", err)
-	}
-
-	return nil
-}
@@ -1,12 +1,65 @@
 package main
 
 import (
+	"io"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
+// copyTestdata copies a testdata directory to a temporary one, so that it can be modified
+func copyTestdata(t *testing.T, src string) string {
+	t.Helper()
+
+	dst := t.TempDir()
+
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0750)
+		}
+
+		content, err := os.ReadFile(path) //nolint:gosec
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(filepath.Join(dst, rel), content, info.Mode())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dst
+}
+
+// versionTestdata creates a directory whose config only reads the version from an environment variable, set to
+// version, for the commands that don't need any input or output file
+func versionTestdata(t *testing.T, version string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	config := `{"version_input_env_var": {"env_var": "TEST_VERSION", "pattern": "^v?(.+)$"}}`
+
+	if err := os.WriteFile(filepath.Join(dir, ".ci-info.json"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("TEST_VERSION", version)
+
+	return dir
+}
+
 func TestRemoteConfig(t *testing.T) {
 	a := assert.New(t)
 
@@ -72,6 +125,33 @@ func TestMainRunNpm(t *testing.T) {
 	a.FileExists("testdata/npm/version.txt")
 }
 
+func TestMainRunPnpmWorkspace(t *testing.T) {
+	a := assert.New(t)
+
+	a.NoError(runMain([]string{"-c", "testdata/pnpm/.ci-info.json"}))
+
+	content, err := os.ReadFile("testdata/pnpm/version.txt.out")
+	a.NoError(err)
+	a.Contains(string(content), "@acme/web 4.5.6-")
+
+	a.NoError(runMain([]string{"-c", "testdata/pnpm/.ci-info.json", "-p", "@acme/api"}))
+
+	content, err = os.ReadFile("testdata/pnpm/version.txt.out")
+	a.NoError(err)
+	a.Contains(string(content), "@acme/api 1.0.0-")
+}
+
+func TestMainRunNuget(t *testing.T) {
+	a := assert.New(t)
+
+	a.NoError(runMain([]string{"-c", "testdata/nuget/src/App/.ci-info.json"}))
+	a.FileExists("testdata/nuget/src/App/build.json.out")
+
+	content, err := os.ReadFile("testdata/nuget/src/App/version.txt.out")
+	a.NoError(err)
+	a.Contains(string(content), "2.4.0-beta1-")
+}
+
 func TestRunStandardOne(t *testing.T) {
 	a := assert.New(t)
 
@@ -109,6 +189,168 @@ func TestMainInit(t *testing.T) {
 
 	a.NoError(runMain([]string{"-i", "-c", "testdata/.ci-info.init.out"}))
 	a.FileExists("testdata/.ci-info.init.out")
+
+	// The sections of the optional features aren't written
+	content, err := os.ReadFile("testdata/.ci-info.init.out")
+	a.NoError(err)
+	a.NotContains(string(content), `"shell_env"`)
+	a.NotContains(string(content), `"ldflags"`)
+	a.NotContains(string(content), `"docker"`)
+	a.NotContains(string(content), `"check"`)
+}
+
+func TestMainCheck(t *testing.T) {
+	a := assert.New(t)
+
+	dir := copyTestdata(t, "testdata/npm")
+	configFile := filepath.Join(dir, ".ci-info.json")
+
+	a.NoError(runMain([]string{"-c", configFile}))
+	a.NoError(runMain([]string{"-c", configFile, "check", "outputs", "-l", "debug", "-ignore", "build_date"}))
+
+	a.NoError(os.Remove(filepath.Join(dir, "version.txt")))
+	a.ErrorIs(runMain([]string{"-c", configFile, "check", "outputs", "-ignore", "BuildDate"}), ErrOutputDrift)
+	a.ErrorIs(runMain([]string{"-c", configFile, "check", "everything"}), errUnknownCheck)
+	a.ErrorIs(runMain([]string{"unknown"}), errUnknownCommand)
+}
+
+// TestMainCheckVersionOnTag checks that the version check of a tag pipeline doesn't depend on the output files
+func TestMainCheckVersionOnTag(t *testing.T) {
+	a := assert.New(t)
+	dir := copyTestdata(t, "testdata/npm")
+	configFile := filepath.Join(dir, ".ci-info.json")
+
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_SHA", "f96a756e2b1c8d2a7f5e3b0c9d8e7f6a5b4c3d2e")
+	t.Setenv("GITHUB_REF", "refs/tags/v1.2.3")
+
+	a.NoError(os.WriteFile(configFile, []byte(`{
+		"version_input_git_tag": {"pattern": "^v?([0-9.]+)$"},
+		"templates": [{"input_content": "package main\n\nconst Version = \"{{ .Version }}\"\n", "output_file": "build.go"}]
+	}`), 0600))
+	a.NoError(os.WriteFile(filepath.Join(dir, "build.go"), []byte("package main\n\nconst Version = \"1.2.2\"\n"), 0600))
+
+	output, err := captureStdout(t, func() error { return runMain([]string{"-c", configFile, "check"}) })
+	a.NoError(err)
+	a.Equal("tag: 1.2.3\nnpm: 1.2.3\n", output)
+
+	_, err = captureStdout(t, func() error { return runMain([]string{"-c", configFile, "check", "version"}) })
+	a.NoError(err)
+
+	a.ErrorIs(runMain([]string{"-c", configFile, "check", "outputs"}), ErrOutputDrift)
+
+	t.Setenv("GITHUB_REF", "refs/tags/v1.2.4")
+
+	_, err = captureStdout(t, func() error { return runMain([]string{"-c", configFile, "check"}) })
+	a.ErrorIs(err, ErrVersionMismatch)
+}
+
+func TestMainCheckDrift(t *testing.T) {
+	a := assert.New(t)
+	dir := copyTestdata(t, "testdata/drift")
+	configFile := filepath.Join(dir, ".ci-info.json")
+
+	t.Setenv("DRIFT_VERSION", "1.2.3")
+	a.NoError(runMain([]string{"-c", configFile}))
+	a.NoError(runMain([]string{"-c", configFile, "--check", "--ignore", "build_date"}))
+
+	// The file isn't modified by the check
+	before, err := os.ReadFile(filepath.Join(dir, "version.go"))
+	a.NoError(err)
+
+	t.Setenv("DRIFT_VERSION", "1.2.4")
+	a.ErrorIs(runMain([]string{"-c", configFile, "--check", "--ignore", "BuildDate"}), ErrOutputDrift)
+
+	after, err := os.ReadFile(filepath.Join(dir, "version.go"))
+	a.NoError(err)
+	a.Equal(before, after)
+
+	a.ErrorIs(runMain([]string{"-c", configFile, "--check", "--ignore", "NotAField"}), errUnknownField)
+}
+
+// captureStdout returns what f writes to the standard output
+func captureStdout(t *testing.T, f func() error) (string, error) {
+	t.Helper()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = writer
+
+	defer func() { os.Stdout = stdout }()
+
+	errF := f()
+
+	_ = writer.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(content), errF
+}
+
+func TestMainGetAndPrint(t *testing.T) {
+	a := assert.New(t)
+	configFile := filepath.Join(copyTestdata(t, "testdata/drift"), ".ci-info.json")
+
+	t.Setenv("DRIFT_VERSION", "v1.2.3")
+
+	output, err := captureStdout(t, func() error { return runMain([]string{"-c", configFile, "get", "version"}) })
+	a.NoError(err)
+	a.Equal("1.2.3\n", output)
+
+	output, err = captureStdout(t, func() error {
+		return runMain([]string{"-c", configFile, "get", "VersionDeclared", "package_manager"})
+	})
+	a.NoError(err)
+	a.Equal("1.2.3\n\n", output)
+
+	output, err = captureStdout(t, func() error { return runMain([]string{"-c", configFile, "get"}) })
+	a.NoError(err)
+	a.Contains(output, "version=1.2.3\n")
+	a.Contains(output, "git_commit_hash_short=")
+
+	_, err = captureStdout(t, func() error { return runMain([]string{"-c", configFile, "get", "nope"}) })
+	a.ErrorIs(err, errUnknownField)
+
+	output, err = captureStdout(t, func() error { return runMain([]string{"-c", configFile, "print", "version.go"}) })
+	a.NoError(err)
+	a.Contains(output, "const Version = \"1.2.3\"\n")
+
+	output, err = captureStdout(t, func() error { return runMain([]string{"-c", configFile, "print", "v{{ .Version }}"}) })
+	a.NoError(err)
+	a.Equal("v1.2.3", output)
+
+	output, err = captureStdout(t, func() error { return runMain([]string{"-c", configFile, "print", "dotenv"}) })
+	a.NoError(err)
+	a.Contains(output, "BUILD_VERSION=\"1.2.3\"\n")
+
+	_, err = captureStdout(t, func() error { return runMain([]string{"-c", configFile, "print"}) })
+	a.ErrorIs(err, errMissingArgument)
+}
+
+func TestMainStdoutAndDryRun(t *testing.T) {
+	a := assert.New(t)
+	dir := copyTestdata(t, "testdata/drift")
+	configFile := filepath.Join(dir, ".ci-info.json")
+
+	t.Setenv("DRIFT_VERSION", "1.2.3")
+
+	output, err := captureStdout(t, func() error { return runMain([]string{"-c", configFile, "--dry-run", "-vf", "-"}) })
+	a.NoError(err)
+	a.Contains(output, "==> "+filepath.Join(dir, "version.go")+" <==\npackage version\n")
+	a.Contains(output, "==> - <==\n1.2.3\n")
+	a.NoFileExists(filepath.Join(dir, "version.go"))
+
+	output, err = captureStdout(t, func() error { return runMain([]string{"-c", configFile, "-b", "-"}) })
+	a.NoError(err)
+	a.Contains(output, "\"version\": \"1.2.3\"")
+	a.FileExists(filepath.Join(dir, "version.go"))
 }
 
 func TestMainLoggingLevel(t *testing.T) {
@@ -120,3 +362,19 @@ func TestMainLoggingLevel(t *testing.T) {
 	a.NoError(runMain([]string{"-l", "error"}))
 	a.Error(runMain([]string{"-l", "bad"}))
 }
+
+func TestMainRunPartials(t *testing.T) {
+	a := assert.New(t)
+	dir := copyTestdata(t, "testdata/partials")
+
+	a.NoError(runMain([]string{"-c", filepath.Join(dir, ".ci-info.json")}))
+
+	content, err := os.ReadFile(filepath.Join(dir, "generated/version.go"))
+	a.NoError(err)
+	a.Contains(string(content), "// Generated by ci-info for version ")
+	a.Contains(string(content), "// Licensed under the MIT license\n")
+
+	content, err = os.ReadFile(filepath.Join(dir, "generated/sub/version.txt"))
+	a.NoError(err)
+	a.Contains(string(content), "Generated by ci-info for version ")
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// stdoutPath is the output path of the standard output
+const stdoutPath = "-"
+
+// defaultFileMode is the mode of the new output files
+const defaultFileMode os.FileMode = 0644
+
+// outputWriter reads and writes the output files, so that they can be generated in memory.
+// A zero mode keeps the mode of an existing file.
+type outputWriter interface {
+	ReadFile(fileName string) ([]byte, error)
+	WriteFile(fileName string, content []byte, mode os.FileMode) error
+}
+
+// diskOutput writes the output files to the disk
+type diskOutput struct{}
+
+func (diskOutput) ReadFile(fileName string) ([]byte, error) {
+	return os.ReadFile(fileName) //nolint:gosec
+}
+
+// WriteFile only writes the files whose content or mode changed, so that their modification time is only updated
+// when needed. The files are written to a temporary file that is then renamed, so that they're never seen half-written.
+func (diskOutput) WriteFile(fileName string, content []byte, mode os.FileMode) error {
+	if fileName == stdoutPath {
+		_, err := os.Stdout.Write(content)
+
+		return err
+	}
+
+	if st, err := os.Stat(fileName); err == nil {
+		if mode == 0 {
+			mode = st.Mode().Perm()
+		}
+
+		if existing, err := os.ReadFile(fileName); err == nil && bytes.Equal(existing, content) { //nolint:gosec
+			if st.Mode().Perm() == mode {
+				log.Debug("File is unchanged", "path", fileName)
+
+				return nil
+			}
+
+			return os.Chmod(fileName, mode)
+		}
+	}
+
+	if mode == 0 {
+		mode = defaultFileMode
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil { //nolint:gosec
+		return fmt.Errorf("could not create output directory: %w", err)
+	}
+
+	return writeFileAtomically(fileName, content, mode)
+}
+
+func writeFileAtomically(fileName string, content []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(fileName), "."+filepath.Base(fileName)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("could not create temporary file: %w", err)
+	}
+
+	// Removing the temporary file fails once it has been renamed
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+
+		return fmt.Errorf("could not write temporary file: %w", err)
+	}
+
+	if err := tmp.Chmod(mode); err != nil {
+		_ = tmp.Close()
+
+		return fmt.Errorf("could not set file mode: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not close temporary file: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), fileName)
+}
+
+// dryRunOutput prints the output files instead of writing them
+type dryRunOutput struct{}
+
+func (dryRunOutput) ReadFile(fileName string) ([]byte, error) {
+	return os.ReadFile(fileName) //nolint:gosec
+}
+
+func (dryRunOutput) WriteFile(fileName string, content []byte, _ os.FileMode) error {
+	fmt.Printf("==> %s <==\n%s", fileName, content)
+
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// memoryOutput keeps the output files in memory, the files it doesn't have are read from the disk
+type memoryOutput struct {
+	files     map[string][]byte
+	fileNames []string
+}
+
+func newMemoryOutput() *memoryOutput {
+	return &memoryOutput{files: map[string][]byte{}}
+}
+
+func (m *memoryOutput) ReadFile(fileName string) ([]byte, error) {
+	if content, ok := m.files[fileName]; ok {
+		return content, nil
+	}
+
+	return os.ReadFile(fileName) //nolint:gosec
+}
+
+func (m *memoryOutput) WriteFile(fileName string, content []byte, _ os.FileMode) error {
+	if _, ok := m.files[fileName]; !ok {
+		m.fileNames = append(m.fileNames, fileName)
+	}
+
+	m.files[fileName] = content
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskOutput(t *testing.T) {
+	a := require.New(t)
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "sub", "dir", "build.go")
+
+	// The parent directories are created
+	a.NoError(diskOutput{}.WriteFile(fileName, []byte("v1"), 0))
+
+	st, err := os.Stat(fileName)
+	a.NoError(err)
+	a.Equal(defaultFileMode, st.Mode().Perm())
+
+	// An unchanged file isn't rewritten
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	a.NoError(os.Chtimes(fileName, past, past))
+	a.NoError(diskOutput{}.WriteFile(fileName, []byte("v1"), 0))
+
+	st, err = os.Stat(fileName)
+	a.NoError(err)
+	a.Equal(past, st.ModTime())
+
+	// A mode change is applied
+	a.NoError(diskOutput{}.WriteFile(fileName, []byte("v1"), 0755))
+
+	st, err = os.Stat(fileName)
+	a.NoError(err)
+	a.Equal(os.FileMode(0755), st.Mode().Perm())
+
+	// The mode of an existing file is kept
+	a.NoError(diskOutput{}.WriteFile(fileName, []byte("v2"), 0))
+
+	st, err = os.Stat(fileName)
+	a.NoError(err)
+	a.Equal(os.FileMode(0755), st.Mode().Perm())
+
+	content, err := os.ReadFile(fileName)
+	a.NoError(err)
+	a.Equal("v2", string(content))
+
+	// No temporary file is left
+	entries, err := os.ReadDir(filepath.Dir(fileName))
+	a.NoError(err)
+	a.Len(entries, 1)
+}
+
+func TestTemplateFileMode(t *testing.T) {
+	a := require.New(t)
+
+	mode, err := (&ConfigTemplate{}).fileMode()
+	a.NoError(err)
+	a.Equal(os.FileMode(0), mode)
+
+	mode, err = (&ConfigTemplate{Mode: "0755"}).fileMode()
+	a.NoError(err)
+	a.Equal(os.FileMode(0755), mode)
+
+	_, err = (&ConfigTemplate{Mode: "rwx"}).fileMode()
+	a.ErrorIs(err, errInvalidMode)
+
+	_, err = (&ConfigTemplate{Mode: "1777"}).fileMode()
+	a.ErrorIs(err, errInvalidMode)
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	errNoDebianPackage    = errors.New("no debian package name, set debian.package")
+	errNoDebianMaintainer = errors.New("no debian maintainer, set debian.maintainer or DEBFULLNAME and DEBEMAIL")
+)
+
+const (
+	defaultDebianChangelog    = "debian/changelog"
+	defaultDebianDistribution = "unstable"
+	defaultDebianUrgency      = "medium"
+	defaultDebianRevision     = "1"
+	defaultRPMRelease         = "1"
+
+	// rfc2822Format is the date format of the debian changelog trailer lines
+	rfc2822Format = "Mon, 02 Jan 2006 15:04:05 -0700"
+)
+
+var (
+	reDebianStanzaHeader  = regexp.MustCompile(`^(\S+) \(([^)]+)\)`)
+	reDebianTrailer       = regexp.MustCompile(`(?m)^ -- (.+?)  \S.*$`)
+	reInvalidVersionChars = regexp.MustCompile(`[^A-Za-z0-9.+~]+`)
+	reRPMRelease          = regexp.MustCompile(`(?m)^Release:\s*(.*?)\s*$`)
+)
+
+// debianVersion returns the upstream version following the debian rules: the pre-releases sort before the release
+// with "~", and the other separators become dots. 0.1.0-feature-x-6ea1772 becomes 0.1.0~feature.x.6ea1772.
+func debianVersion(version string) (string, error) {
+	v, err := parseVersion(version)
+	if err != nil {
+		return "", err
+	}
+
+	s := v.Core()
+
+	if v.Revision != 0 {
+		s += fmt.Sprintf(".%d", v.Revision)
+	}
+
+	if v.Prerelease != "" {
+		s += "~" + strings.Trim(reInvalidVersionChars.ReplaceAllString(v.Prerelease, "."), ".")
+	}
+
+	if v.Metadata != "" {
+		s += "+" + strings.Trim(reInvalidVersionChars.ReplaceAllString(v.Metadata, "."), ".")
+	}
+
+	return s, nil
+}
+
+// rpmVersion returns the version following the RPM rules, which are the debian ones since "-" isn't allowed either
+func rpmVersion(version string) (string, error) {
+	return debianVersion(version)
+}
+
+// saveDebianChangelog prepends a stanza for the current version to the debian changelog, or replaces it if the
+// changelog already starts with this version
+func saveDebianChangelog(config *Config, buildInfo *BuildInfo) error {
+	debian := config.Debian
+
+	fileName := path.Join(config.Directory, valueOrDefault(debian.Changelog, defaultDebianChangelog))
+
+	content, err := config.writer().ReadFile(fileName)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	stanza, err := debian.stanza(config.Directory, buildInfo, string(content))
+	if err != nil {
+		return err
+	}
+
+	// The first stanza is replaced if it's the one of the current version
+	existing := string(content)
+	if header := reDebianStanzaHeader.FindStringSubmatch(existing); header != nil &&
+		strings.HasPrefix(stanza, header[0]) {
+		if trailer := reDebianTrailer.FindStringIndex(existing); trailer != nil {
+			existing = strings.TrimLeft(existing[trailer[1]:], "\n")
+		}
+	}
+
+	updated := stanza
+	if existing != "" {
+		updated += "\n" + existing
+	}
+
+	if updated == string(content) {
+		return nil
+	}
+
+	log.Debug("Updating debian changelog", "path", fileName)
+
+	return config.writer().WriteFile(fileName, []byte(updated), 0)
+}
+
+// stanza returns the changelog entry of the current version, listing the commits since the last tag
+func (d *ConfigDebian) stanza(dir string, buildInfo *BuildInfo, changelog string) (string, error) {
+	upstreamVersion, err := debianVersion(buildInfo.Version)
+	if err != nil {
+		return "", err
+	}
+
+	version := upstreamVersion
+
+	revision := defaultDebianRevision
+	if d.Revision != nil {
+		revision = *d.Revision
+	}
+
+	if revision != "" {
+		version += "-" + revision
+	}
+
+	header := reDebianStanzaHeader.FindStringSubmatch(changelog)
+
+	pkg := valueOrDefault(d.Package, buildInfo.PackageName)
+	if pkg == "" && header != nil {
+		pkg = header[1]
+	}
+
+	if pkg == "" {
+		return "", errNoDebianPackage
+	}
+
+	maintainer := d.maintainer(changelog)
+	if maintainer == "" {
+		return "", errNoDebianMaintainer
+	}
+
+	date, err := time.Parse(time.RFC3339, buildInfo.BuildDate)
+	if err != nil {
+		date = time.Now()
+	}
+
+	subjects, err := getCommitSubjectsSinceLastTag(dir)
+	if err != nil {
+		log.Warn("Could not list the commits since the last tag", "err", err)
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s (%s) %s; urgency=%s\n\n", pkg, version,
+		valueOrDefault(d.Distribution, defaultDebianDistribution), valueOrDefault(d.Urgency, defaultDebianUrgency))
+
+	entries := 0
+
+	for _, subject := range subjects {
+		if subject != "" {
+			fmt.Fprintf(&b, "  * %s\n", subject)
+			entries++
+		}
+	}
+
+	if entries == 0 {
+		fmt.Fprintf(&b, "  * Release %s\n", upstreamVersion)
+	}
+
+	fmt.Fprintf(&b, "\n -- %s  %s\n", maintainer, date.Format(rfc2822Format))
+
+	return b.String(), nil
+}
+
+// maintainer returns the configured maintainer, the one of the debian tools environment variables or the one of
+// the last changelog entry
+func (d *ConfigDebian) maintainer(changelog string) string {
+	if d.Maintainer != "" {
+		return d.Maintainer
+	}
+
+	if name, email := os.Getenv("DEBFULLNAME"), os.Getenv("DEBEMAIL"); name != "" && email != "" {
+		return name + " <" + email + ">"
+	}
+
+	if trailer := reDebianTrailer.FindStringSubmatch(changelog); trailer != nil {
+		return trailer[1]
+	}
+
+	return ""
+}
+
+// saveRPMSpecFiles sets the Version and Release of the spec files, a "%{?dist}" suffix of the release being kept
+func saveRPMSpecFiles(config *Config, buildInfo *BuildInfo) error {
+	version, err := rpmVersion(buildInfo.Version)
+	if err != nil {
+		return err
+	}
+
+	release, err := renderTemplate(valueOrDefault(config.RPM.Release, defaultRPMRelease), buildInfo)
+	if err != nil {
+		return fmt.Errorf("failed to render release: %w", err)
+	}
+
+	for _, specFile := range config.RPM.SpecFiles {
+		fileName := path.Join(config.Directory, specFile)
+
+		content, err := config.writer().ReadFile(fileName)
+		if err != nil {
+			return err
+		}
+
+		updated, err := replacePattern(string(content), `^Version:\s*(.*?)\s*$`, version)
+		if err != nil {
+			return fmt.Errorf("could not update %s: %w", fileName, err)
+		}
+
+		specRelease := release
+		if current := reRPMRelease.FindStringSubmatch(updated); current != nil &&
+			strings.HasSuffix(current[1], "%{?dist}") && !strings.HasSuffix(release, "%{?dist}") {
+			specRelease += "%{?dist}"
+		}
+
+		if updated, err = replacePattern(updated, `^Release:\s*(.*?)\s*$`, specRelease); err != nil {
+			return fmt.Errorf("could not update %s: %w", fileName, err)
+		}
+
+		if updated == string(content) {
+			continue
+		}
+
+		log.Debug("Updating spec file", "path", fileName)
+
+		if err := config.writer().WriteFile(fileName, []byte(updated), 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+
+	return value
+}
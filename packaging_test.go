@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebianVersion(t *testing.T) {
+	a := require.New(t)
+
+	for input, expected := range map[string]string{
+		"1.2.3":                   "1.2.3",
+		"v1.2.3":                  "1.2.3",
+		"1.2.3-rc.1":              "1.2.3~rc.1",
+		"0.1.0-feature-x-6ea1772": "0.1.0~feature.x.6ea1772",
+		"1.2.3-beta.2+build.45":   "1.2.3~beta.2+build.45",
+	} {
+		version, err := debianVersion(input)
+		a.NoError(err, input)
+		a.Equal(expected, version, input)
+
+		version, err = rpmVersion(input)
+		a.NoError(err, input)
+		a.NotContains(version, "-", input)
+	}
+
+	_, err := debianVersion("main")
+	a.ErrorIs(err, ErrInvalidVersion)
+}
+
+func TestDebianChangelog(t *testing.T) {
+	a := require.New(t)
+	dir := initTestRepo(t, []string{"Initial commit", "Add feature", "Fix bug"}, map[int]string{0: "v1.0.0"})
+	config := &Config{Directory: dir, Debian: &ConfigDebian{Package: "ci-info", Maintainer: "Jane Doe <jane@example.com>"}}
+	bi := &BuildInfo{Version: "1.1.0-rc.1", BuildDate: "2024-03-05T10:20:30+01:00"}
+	previous := "ci-info (1.0.0-1) unstable; urgency=medium\n\n  * Initial release\n\n" +
+		" -- John Doe <john@example.com>  Mon, 01 Jan 2024 10:00:00 +0000\n"
+	expected := "ci-info (1.1.0~rc.1-1) unstable; urgency=medium\n\n  * Fix bug\n  * Add feature\n\n" +
+		" -- Jane Doe <jane@example.com>  Tue, 05 Mar 2024 10:20:30 +0100\n\n" + previous
+
+	a.NoError(os.MkdirAll(filepath.Join(dir, "debian"), 0750))
+	a.NoError(os.WriteFile(filepath.Join(dir, "debian", "changelog"), []byte(previous), 0600))
+
+	// The entry of the current version is replaced
+	for i := 0; i < 2; i++ {
+		a.NoError(saveDebianChangelog(config, bi))
+
+		content, err := os.ReadFile(filepath.Join(dir, "debian", "changelog")) //nolint:gosec
+		a.NoError(err)
+		a.Equal(expected, string(content))
+	}
+
+	// The package and the maintainer of the last entry are used by default, the native packages have no revision
+	revision := ""
+	config.Debian = &ConfigDebian{Revision: &revision, Distribution: "stable"}
+
+	t.Setenv("DEBFULLNAME", "")
+	a.NoError(os.WriteFile(filepath.Join(dir, "debian", "changelog"), []byte(previous), 0600))
+	a.NoError(saveDebianChangelog(config, bi))
+
+	content, err := os.ReadFile(filepath.Join(dir, "debian", "changelog")) //nolint:gosec
+	a.NoError(err)
+	a.Equal("ci-info (1.1.0~rc.1) stable; urgency=medium\n\n  * Fix bug\n  * Add feature\n\n"+
+		" -- John Doe <john@example.com>  Tue, 05 Mar 2024 10:20:30 +0100\n\n"+previous, string(content))
+
+	a.NoError(os.Remove(filepath.Join(dir, "debian", "changelog")))
+	a.ErrorIs(saveDebianChangelog(config, bi), errNoDebianPackage)
+
+	config.Debian.Package = "ci-info"
+	a.ErrorIs(saveDebianChangelog(config, bi), errNoDebianMaintainer)
+}
+
+func TestMainRPM(t *testing.T) {
+	a := require.New(t)
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, ".ci-info.json")
+
+	t.Setenv("APP_VERSION", "2.0.0-beta.1")
+
+	a.NoError(os.WriteFile(filepath.Join(dir, "app.spec"), []byte(
+		"Name:    app\nVersion: 1.0.0\nRelease: 3%{?dist}\nSummary: App\n",
+	), 0600))
+	a.NoError(os.WriteFile(configFile, []byte(`{
+		"version_input_env_var": {"env_var": "APP_VERSION", "pattern": "^(.+)$"},
+		"rpm": {"spec_files": ["app.spec"]}
+	}`), 0600))
+
+	a.NoError(runMain([]string{"-c", configFile}))
+
+	content, err := os.ReadFile(filepath.Join(dir, "app.spec")) //nolint:gosec
+	a.NoError(err)
+	a.Equal("Name:    app\nVersion: 2.0.0~beta.1\nRelease: 1%{?dist}\nSummary: App\n", string(content))
+
+	a.NoError(os.WriteFile(filepath.Join(dir, "app.spec"), []byte("Name: app\n"), 0600))
+	a.ErrorIs(runMain([]string{"-c", configFile}), errPatternNotFound)
+}
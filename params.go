@@ -2,26 +2,83 @@ package main
 
 import "flag"
 
+const (
+	cmdCheck      = "check"
+	cmdPrint      = "print"
+	cmdGet        = "get"
+	cmdEnv        = "env"
+	cmdLDFlags    = "ldflags"
+	cmdDocker     = "docker"
+	cmdProvenance = "provenance"
+	cmdVerify     = "verify"
+)
+
 // CmdParams contains the command line parameters
 type CmdParams struct {
 	ConfigFile          string
 	OutputBuildInfoFile string
+	BuildInfoFormat     string
 	OutputVersionFile   string
 	LoggingLevel        string
+	Package             string
+	Command             string
+	Args                []string
+	Variables           variablesFlag
 	Version             bool
+	Check               bool
+	DryRun              bool
+	CIExport            bool
+	Shell               string
+	Prefix              string
+	Fields              string
+	CheckIgnore         string
+	Key                 string
 	Init                bool
+	InitTemplates       string
 }
 
 func getParams(args []string) (*CmdParams, error) {
 	fs := flag.NewFlagSet("ci-info", flag.ContinueOnError)
 
-	params := &CmdParams{}
+	params := &CmdParams{Variables: variablesFlag{}}
 	fs.StringVar(&params.ConfigFile, "c", "", "config file")
 	fs.BoolVar(&params.Version, "v", false, "version")
 	fs.StringVar(&params.OutputBuildInfoFile, "b", "", "build info file")
+	fs.StringVar(&params.BuildInfoFormat, "bf", "", "build info format (json, yaml, toml, env, properties, xml)")
 	fs.StringVar(&params.OutputVersionFile, "vf", "", "version file")
 	fs.StringVar(&params.LoggingLevel, "l", "info", "logging level")
 	fs.BoolVar(&params.Init, "i", false, "init config file")
+	fs.StringVar(&params.InitTemplates, "t", "", "builtin templates of the init config file")
+	fs.StringVar(&params.Package, "p", "", "package to select in a workspace")
+	fs.StringVar(&params.Shell, "shell", "", "shell of the env command (sh, fish, powershell)")
+	fs.StringVar(&params.Prefix, "prefix", "", "prefix of the variables of the env command")
+	fs.StringVar(&params.Fields, "fields", "", "fields of the env command (comma separated)")
+	fs.BoolVar(&params.CIExport, "ci-export", false, "publish the build info to the CI")
+	fs.BoolVar(&params.DryRun, "dry-run", false, "print the output files instead of writing them")
+	fs.BoolVar(&params.Check, "check", false, "check that the output files are up to date")
+	fs.StringVar(&params.CheckIgnore, "ignore", "", "build info fields ignored by the check (comma separated)")
+	fs.StringVar(&params.Key, "key", "", "signing key file or env://VAR, the public key for the verify command")
+	fs.Var(params.Variables, "set", "variable to set, as key=value (repeatable)")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// The first argument is the command, which can be followed by flags
+	if fs.NArg() > 0 {
+		params.Command = fs.Arg(0)
+
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return nil, err
+		}
+
+		params.Args = fs.Args()
+	}
+
+	if params.Check && params.Command == "" {
+		params.Command = cmdCheck
+		params.Args = []string{checkOutputs}
+	}
 
-	return params, fs.Parse(args)
+	return params, nil
 }
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var errInvalidPlist = errors.New("invalid plist")
+
+// defaultPlistValues are the versions of the Apple bundles, which only accept numbers
+var defaultPlistValues = map[string]string{
+	"CFBundleShortVersionString": "{{ .VersionCore }}",
+	"CFBundleVersion":            `{{ if ne .VersionBuild "0" }}{{ .VersionBuild }}{{ else }}{{ .VersionCore }}{{ end }}`,
+}
+
+var (
+	rePlistToken  = regexp.MustCompile(`<dict>|</dict>|<dict/>|<key>([^<]*)</key>`)
+	rePlistString = regexp.MustCompile(`^\s*(?:<string>[^<]*</string>|<string/>)`)
+	rePlistIndent = regexp.MustCompile(`\n([ \t]*)<key>`)
+)
+
+// savePlists updates the values of the Info.plist files, the values of the config being added to the default ones
+func savePlists(config *Config, buildInfo *BuildInfo) error {
+	for _, plist := range config.Plists {
+		templates := map[string]string{}
+
+		for key, value := range defaultPlistValues {
+			templates[key] = value
+		}
+
+		// An empty value removes a default one
+		for key, value := range plist.Values {
+			templates[key] = value
+		}
+
+		values := map[string]string{}
+
+		for key, value := range templates {
+			if value == "" {
+				continue
+			}
+
+			rendered, err := renderTemplate(value, buildInfo)
+			if err != nil {
+				return fmt.Errorf("failed to render value for %s: %w", key, err)
+			}
+
+			values[key] = rendered
+		}
+
+		fileName := path.Join(config.Directory, plist.File)
+
+		content, err := config.writer().ReadFile(fileName)
+		if err != nil {
+			return err
+		}
+
+		updated, err := setPlistValues(content, values)
+		if err != nil {
+			return fmt.Errorf("could not update %s: %w", fileName, err)
+		}
+
+		if bytes.Equal(content, updated) {
+			continue
+		}
+
+		log.Debug("Updating plist file", "path", fileName)
+
+		if err := config.writer().WriteFile(fileName, updated, 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setPlistValues sets string values of the root dictionary of an XML plist. The existing values are replaced,
+// the other ones are added at the end of the dictionary, so that the rest of the file is preserved.
+func setPlistValues(content []byte, values map[string]string) ([]byte, error) {
+	if bytes.HasPrefix(content, []byte("bplist")) {
+		return nil, fmt.Errorf("%w: binary plists aren't supported", errInvalidPlist)
+	}
+
+	s := string(content)
+	missing := map[string]bool{}
+
+	for key := range values {
+		missing[key] = true
+	}
+
+	var b strings.Builder
+
+	depth, last, end := 0, 0, -1
+
+	for _, match := range rePlistToken.FindAllStringSubmatchIndex(s, -1) {
+		token := s[match[0]:match[1]]
+
+		switch {
+		case token == "<dict>":
+			depth++
+		case token == "</dict>":
+			depth--
+		case token == "<dict/>" || depth != 1:
+		default:
+			key := s[match[2]:match[3]]
+			value, ok := values[key]
+
+			if !ok || !missing[key] {
+				continue
+			}
+
+			stringMatch := rePlistString.FindStringIndex(s[match[1]:])
+			if stringMatch == nil {
+				return nil, fmt.Errorf("%w: %s isn't a string", errInvalidPlist, key)
+			}
+
+			b.WriteString(s[last:match[1]])
+			b.WriteString(s[match[1] : match[1]+strings.Index(s[match[1]:], "<")])
+			b.WriteString("<string>" + xmlEscape(value) + "</string>")
+
+			last = match[1] + stringMatch[1]
+			missing[key] = false
+		}
+
+		if depth == 0 && token == "</dict>" {
+			end = match[0]
+
+			break
+		}
+	}
+
+	if end < 0 {
+		return nil, fmt.Errorf("%w: no root dictionary", errInvalidPlist)
+	}
+
+	b.WriteString(s[last:end])
+
+	indent := "\t"
+	if matches := rePlistIndent.FindStringSubmatch(s); matches != nil {
+		indent = matches[1]
+	}
+
+	for _, key := range sortedKeys(values) {
+		if missing[key] {
+			b.WriteString(indent + "<key>" + xmlEscape(key) + "</key>\n")
+			b.WriteString(indent + "<string>" + xmlEscape(values[key]) + "</string>\n")
+		}
+	}
+
+	b.WriteString(s[end:])
+
+	return []byte(b.String()), nil
+}
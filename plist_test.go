@@ -0,0 +1,108 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const samplePlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+    <key>CFBundleName</key>
+    <string>App</string>
+    <key>NSAppTransportSecurity</key>
+    <dict>
+        <key>CFBundleVersion</key>
+        <string>nested</string>
+    </dict>
+    <key>CFBundleShortVersionString</key>
+    <string>$(MARKETING_VERSION)</string>
+    <key>CFBundleVersion</key>
+    <string/>
+</dict>
+</plist>
+`
+
+func TestSetPlistValues(t *testing.T) {
+	a := require.New(t)
+
+	updated, err := setPlistValues([]byte(samplePlist), map[string]string{
+		"CFBundleShortVersionString": "1.2.3",
+		"CFBundleVersion":            "45",
+		"NSHumanReadableCopyright":   "© 2024 Acme & co",
+	})
+	a.NoError(err)
+	a.Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+    <key>CFBundleName</key>
+    <string>App</string>
+    <key>NSAppTransportSecurity</key>
+    <dict>
+        <key>CFBundleVersion</key>
+        <string>nested</string>
+    </dict>
+    <key>CFBundleShortVersionString</key>
+    <string>1.2.3</string>
+    <key>CFBundleVersion</key>
+    <string>45</string>
+    <key>NSHumanReadableCopyright</key>
+    <string>© 2024 Acme &amp; co</string>
+</dict>
+</plist>
+`, string(updated))
+
+	_, err = setPlistValues([]byte(samplePlist), map[string]string{"NSAppTransportSecurity": "none"})
+	a.ErrorIs(err, errInvalidPlist)
+
+	_, err = setPlistValues([]byte("bplist00"), map[string]string{"CFBundleVersion": "45"})
+	a.ErrorIs(err, errInvalidPlist)
+}
+
+func TestLoadNumericVersion(t *testing.T) {
+	a := require.New(t)
+
+	for _, c := range []struct {
+		bi                   BuildInfo
+		core, build, numeric string
+	}{
+		{BuildInfo{Version: "0.1.0-feature-x-6ea1772", CIBuildNumber: "45"}, "0.1.0", "45", "0.1.0.45"},
+		{BuildInfo{VersionDeclared: "2.3", Version: "2.3-main-6ea1772", BuildNumber: "7", CIBuildNumber: "45"}, "2.3.0", "7", "2.3.0.7"},
+		{BuildInfo{Version: "1.2.3.4", CIBuildNumber: "45"}, "1.2.3", "4", "1.2.3.4"},
+		{BuildInfo{Version: "1.2.3", CIBuildNumber: "7352361789"}, "1.2.3", "7352361789", "1.2.3.0"},
+		{BuildInfo{Version: "main-6ea1772"}, "0.0.0", "0", "0.0.0.0"},
+	} {
+		bi := c.bi
+		bi.loadNumericVersion()
+		a.Equal([]string{c.core, c.build, c.numeric}, []string{bi.VersionCore, bi.VersionBuild, bi.VersionNumeric}, c.bi.Version)
+	}
+}
+
+func TestMainPlists(t *testing.T) {
+	a := require.New(t)
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, ".ci-info.json")
+
+	t.Setenv("APP_VERSION", "1.4.0")
+	t.Setenv("APP_BUILD", "12")
+
+	a.NoError(os.WriteFile(filepath.Join(dir, "Info.plist"), []byte(samplePlist), 0600))
+	a.NoError(os.WriteFile(configFile, []byte(`{
+		"version_input_env_var": {"env_var": "APP_VERSION", "pattern": "^(.+)$"},
+		"env_vars": ["APP_BUILD"],
+		"variables": {"build": "{{ env \"APP_BUILD\" }}"},
+		"plists": [{"file": "Info.plist", "values": {"CFBundleVersion": "{{ .Custom.build }}"}}]
+	}`), 0600))
+
+	a.NoError(runMain([]string{"-c", configFile}))
+
+	content, err := os.ReadFile(filepath.Join(dir, "Info.plist")) //nolint:gosec
+	a.NoError(err)
+	a.Contains(string(content), "<key>CFBundleShortVersionString</key>\n    <string>1.4.0</string>")
+	a.Contains(string(content), "<key>CFBundleVersion</key>\n    <string>12</string>")
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var errNoProvenanceSubject = errors.New("no artifact to attest, set the provenance subjects or give them as arguments")
+
+const (
+	inTotoStatementType = "https://in-toto.io/Statement/v1"
+	slsaProvenanceType  = "https://slsa.dev/provenance/v1"
+	provenanceBuildType = "https://github.com/fclairamb/ci-info/provenance/v1"
+)
+
+// provenanceBuilderIDs identify the builder of each CI solution, the local builds being identified by
+// the host name
+var provenanceBuilderIDs = map[string]string{
+	"circleci":        "https://circleci.com",
+	"github-actions":  "https://github.com/actions/runner",
+	"gitlab":          "https://gitlab.com/gitlab-org/gitlab-runner",
+	"drone":           "https://drone.io",
+	"travis":          "https://travis-ci.com",
+	"jenkins":         "https://www.jenkins.io",
+	"azure-pipelines": "https://github.com/microsoft/azure-pipelines-agent",
+	"buildkite":       "https://buildkite.com/docs/agent",
+}
+
+// inTotoStatement is an in-toto attestation,
+// see https://github.com/in-toto/attestation/blob/main/spec/v1/statement.md
+type inTotoStatement struct {
+	Type          string                   `json:"_type"`
+	Subject       []slsaResourceDescriptor `json:"subject"`
+	PredicateType string                   `json:"predicateType"`
+	Predicate     slsaProvenance           `json:"predicate"`
+}
+
+// slsaProvenance is a SLSA provenance predicate, see https://slsa.dev/spec/v1.0/provenance
+type slsaProvenance struct {
+	BuildDefinition slsaBuildDefinition `json:"buildDefinition"`
+	RunDetails      slsaRunDetails      `json:"runDetails"`
+}
+
+type slsaBuildDefinition struct {
+	BuildType            string                   `json:"buildType"`
+	ExternalParameters   map[string]string        `json:"externalParameters"`
+	InternalParameters   map[string]string        `json:"internalParameters,omitempty"`
+	ResolvedDependencies []slsaResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+type slsaRunDetails struct {
+	Builder  slsaBuilder        `json:"builder"`
+	Metadata *slsaBuildMetadata `json:"metadata,omitempty"`
+}
+
+type slsaBuilder struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version,omitempty"`
+}
+
+type slsaBuildMetadata struct {
+	InvocationID string `json:"invocationId,omitempty"`
+	StartedOn    string `json:"startedOn,omitempty"`
+}
+
+type slsaResourceDescriptor struct {
+	Name   string            `json:"name,omitempty"`
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// runProvenance writes the provenance statement of the artifacts given as arguments or as subjects
+// of the config
+func runProvenance(config *Config, args []string) error {
+	provenanceConfig := ConfigProvenance{}
+	if config.Provenance != nil {
+		provenanceConfig = *config.Provenance
+	}
+
+	if len(args) > 0 {
+		provenanceConfig.Subjects = args
+	}
+
+	buildInfo, err := generateBuildInfo(config)
+	if err != nil {
+		return err
+	}
+
+	subjects, err := provenanceSubjects(config.Directory, provenanceConfig.Subjects)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(newProvenanceStatement(buildInfo, &provenanceConfig, subjects), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	outputFile := provenanceConfig.OutputFile
+	if outputFile == "" {
+		outputFile = stdoutPath
+	}
+
+	return config.writer().WriteFile(config.outputPath(outputFile), append(content, '\n'), 0)
+}
+
+// provenanceSubjects returns the sha256 digest of the files matching the patterns
+func provenanceSubjects(dir string, patterns []string) ([]slsaResourceDescriptor, error) {
+	var subjects []slsaResourceDescriptor
+
+	for _, pattern := range patterns {
+		matches, err := expandGlob(dir, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid subjects %s: %w", pattern, err)
+		}
+
+		found := false
+
+		for _, match := range matches {
+			if !isFile(filepath.Join(dir, match)) {
+				continue
+			}
+
+			digest, err := sha256File(filepath.Join(dir, match))
+			if err != nil {
+				return nil, err
+			}
+
+			found = true
+			subjects = append(subjects, slsaResourceDescriptor{Name: match, Digest: map[string]string{"sha256": digest}})
+		}
+
+		if !found {
+			return nil, fmt.Errorf("no artifact found for %s: %w", pattern, os.ErrNotExist)
+		}
+	}
+
+	if len(subjects) == 0 {
+		return nil, errNoProvenanceSubject
+	}
+
+	return subjects, nil
+}
+
+func sha256File(fileName string) (string, error) {
+	file, err := os.Open(fileName) //nolint:gosec
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		if err := file.Close(); err != nil {
+			log.Warn("could not close file", "file", fileName, "err", err)
+		}
+	}()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("could not hash %s: %w", fileName, err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// newProvenanceStatement describes how the subjects were built: from the commit of the repository,
+// by the CI solution
+func newProvenanceStatement(
+	buildInfo *BuildInfo, config *ConfigProvenance, subjects []slsaResourceDescriptor,
+) *inTotoStatement {
+	sourceRef := ""
+	if buildInfo.GitTag != "" {
+		sourceRef = refTags + buildInfo.GitTag
+	} else if buildInfo.GitBranch != "" {
+		sourceRef = refHeads + buildInfo.GitBranch
+	}
+
+	definition := slsaBuildDefinition{
+		BuildType: provenanceBuildType,
+		ExternalParameters: nonEmptyValues(map[string]string{
+			"repository": buildInfo.GitRepositoryURL,
+			"ref":        sourceRef,
+			"version":    buildInfo.Version,
+		}),
+		InternalParameters: nonEmptyValues(map[string]string{
+			"ci_solution":     buildInfo.CISolution,
+			"ci_build_number": buildInfo.CIBuildNumber,
+			"package_manager": buildInfo.PackageManager,
+		}),
+	}
+
+	if buildInfo.GitCommitHash != "" {
+		source := slsaResourceDescriptor{Digest: map[string]string{"gitCommit": buildInfo.GitCommitHash}}
+
+		if buildInfo.GitRepositoryURL != "" {
+			source.URI = "git+" + buildInfo.GitRepositoryURL
+			if sourceRef != "" {
+				source.URI += "@" + sourceRef
+			}
+		}
+
+		definition.ResolvedDependencies = []slsaResourceDescriptor{source}
+	}
+
+	builderID := config.BuilderID
+	if builderID == "" {
+		builderID = provenanceBuilderIDs[buildInfo.CISolution]
+	}
+
+	if builderID == "" {
+		builderID = "urn:ci-info:host:" + buildInfo.BuildHost
+	}
+
+	runDetails := slsaRunDetails{
+		Builder: slsaBuilder{ID: builderID, Version: nonEmptyValues(map[string]string{"ci-info": buildInfo.CIInfoVersion})},
+	}
+
+	metadata := slsaBuildMetadata{InvocationID: buildInfo.CIBuildURL}
+
+	// The build date can be overridden with any format, the timestamps of SLSA are RFC 3339 ones
+	if date, err := time.Parse(time.RFC3339, buildInfo.BuildDate); err == nil {
+		metadata.StartedOn = date.UTC().Format(time.RFC3339)
+	}
+
+	if metadata != (slsaBuildMetadata{}) {
+		runDetails.Metadata = &metadata
+	}
+
+	return &inTotoStatement{
+		Type:          inTotoStatementType,
+		Subject:       subjects,
+		PredicateType: slsaProvenanceType,
+		Predicate:     slsaProvenance{BuildDefinition: definition, RunDetails: runDetails},
+	}
+}
+
+// nonEmptyValues returns the entries of a map that have a value
+func nonEmptyValues(values map[string]string) map[string]string {
+	result := map[string]string{}
+
+	for key, value := range values {
+		if value != "" {
+			result[key] = value
+		}
+	}
+
+	return result
+}
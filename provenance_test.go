@@ -0,0 +1,427 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The statements are checked against the protobuf definitions of in-toto/attestation, vendored in
+// testdata/provenance/in-toto-attestation, with the protobuf JSON mapping. The required fields and the digests are
+// then checked as the Validate methods of the same module do.
+const protoDir = "testdata/provenance/in-toto-attestation"
+
+var (
+	errSpecViolation = errors.New("specification violation")
+	reProtoMessage   = regexp.MustCompile(`(?s)\nmessage (\w+) \{(.*?)\n\}`)
+	reProtoField     = regexp.MustCompile(
+		`(?m)^\s*(repeated\s+)?(map<\s*string\s*,\s*string\s*>|[\w.]+)\s+(\w+)\s*=\s*\d+\s*(?:\[json_name = "(\w+)"\])?;`,
+	)
+)
+
+// digestHexLengths are the lengths of the digests of the algorithms known by in-toto/attestation
+var digestHexLengths = map[string]int{
+	"md5": 32, "sha1": 40, "gitBlob": 40, "gitCommit": 40, "gitTag": 40, "gitTree": 40,
+	"sha224": 56, "sha512_224": 56, "sha3_224": 56, "sha256": 64, "sha512_256": 64, "sha3_256": 64, "dirHash": 64,
+	"sha384": 96, "sha3_384": 96, "sha512": 128, "sha3_512": 128,
+}
+
+type protoField struct {
+	typeName string
+	repeated bool
+}
+
+// protoMessages are the fields of the messages, by their JSON name
+type protoMessages map[string]map[string]protoField
+
+func loadProtoMessages() (protoMessages, error) {
+	messages := protoMessages{}
+
+	for _, file := range []string{"statement.proto", "resource_descriptor.proto", "provenance.proto"} {
+		content, err := os.ReadFile(filepath.Join(protoDir, file)) //nolint:gosec
+		if err != nil {
+			return nil, err
+		}
+
+		for _, message := range reProtoMessage.FindAllStringSubmatch(string(content), -1) {
+			fields := map[string]protoField{}
+
+			for _, field := range reProtoField.FindAllStringSubmatch(message[2], -1) {
+				jsonName := field[4]
+				if jsonName == "" {
+					jsonName = protoJSONName(field[3])
+				}
+
+				fields[jsonName] = protoField{typeName: field[2], repeated: field[1] != ""}
+			}
+
+			messages[message[1]] = fields
+		}
+	}
+
+	return messages, nil
+}
+
+// protoJSONName returns the lowerCamelCase name that protobuf uses in JSON
+func protoJSONName(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+	}
+
+	return strings.Join(parts, "")
+}
+
+// check checks that value is the JSON of the message, without any unknown field
+func (m protoMessages) check(message string, value interface{}, field string) error {
+	fields, ok := m[message]
+	if !ok {
+		return fmt.Errorf("%w: unknown message %s", errSpecViolation, message)
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w: %s isn't a %s object", errSpecViolation, field, message)
+	}
+
+	for _, name := range sortedKeys(object) {
+		f, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("%w: %s.%s isn't a field of %s", errSpecViolation, field, name, message)
+		}
+
+		if !f.repeated {
+			if err := m.checkValue(f.typeName, object[name], field+"."+name); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		values, ok := object[name].([]interface{})
+		if !ok {
+			return fmt.Errorf("%w: %s.%s isn't an array", errSpecViolation, field, name)
+		}
+
+		for i, v := range values {
+			if err := m.checkValue(f.typeName, v, fmt.Sprintf("%s.%s[%d]", field, name, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m protoMessages) checkValue(typeName string, value interface{}, field string) error {
+	valid := true
+
+	switch typeName {
+	case "string":
+		_, valid = value.(string)
+	case "bytes":
+		s, ok := value.(string)
+		_, err := base64.StdEncoding.DecodeString(s)
+		valid = ok && err == nil
+	case "google.protobuf.Struct":
+		_, valid = value.(map[string]interface{})
+	case "google.protobuf.Timestamp":
+		s, ok := value.(string)
+		_, err := time.Parse(time.RFC3339Nano, s)
+		valid = ok && err == nil
+	default:
+		if strings.HasPrefix(typeName, "map<") {
+			entries, ok := value.(map[string]interface{})
+			for _, v := range entries {
+				_, isString := v.(string)
+				ok = ok && isString
+			}
+
+			valid = ok
+
+			break
+		}
+
+		return m.check(typeName[strings.LastIndex(typeName, ".")+1:], value, field)
+	}
+
+	if !valid {
+		return fmt.Errorf("%w: %s isn't a %s", errSpecViolation, field, typeName)
+	}
+
+	return nil
+}
+
+// checkURI checks the TypeURI and ResourceURI fields, which are URIs per RFC 3986
+func checkURI(value, field string) error {
+	if u, err := url.Parse(value); err != nil || u.Scheme == "" {
+		return fmt.Errorf("%w: %s: %q isn't an URI", errSpecViolation, field, value)
+	}
+
+	return nil
+}
+
+// checkResourceDescriptors checks the descriptors of a JSON array, as ResourceDescriptor.Validate does
+func checkResourceDescriptors(value interface{}, field string) error {
+	descriptors, _ := value.([]interface{})
+
+	for i, descriptor := range descriptors {
+		rd, _ := descriptor.(map[string]interface{})
+		digest, _ := rd["digest"].(map[string]interface{})
+
+		if rd["name"] == nil && rd["uri"] == nil && len(digest) == 0 {
+			return fmt.Errorf("%w: %s[%d]: no name, uri or digest", errSpecViolation, field, i)
+		}
+
+		for algorithm, value := range digest {
+			length, known := digestHexLengths[algorithm]
+			if _, err := hex.DecodeString(value.(string)); known && (err != nil || len(value.(string)) != length) {
+				return fmt.Errorf("%w: %s[%d].digest.%s: %q", errSpecViolation, field, i, algorithm, value)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isEmptyObject tells if a message is missing, the protobuf messages without any field being the same
+func isEmptyObject(value interface{}) bool {
+	object, _ := value.(map[string]interface{})
+
+	return len(object) == 0
+}
+
+// checkProvenance checks a statement against the in-toto statement and SLSA provenance specifications
+func checkProvenance(content []byte) error { //nolint:gocyclo
+	messages, err := loadProtoMessages()
+	if err != nil {
+		return err
+	}
+
+	var statement map[string]interface{}
+	if err := json.Unmarshal(content, &statement); err != nil {
+		return err
+	}
+
+	if err := messages.check("Statement", statement, "statement"); err != nil {
+		return err
+	}
+
+	subjects, _ := statement["subject"].([]interface{})
+
+	switch {
+	case statement["_type"] != "https://in-toto.io/Statement/v1":
+		return fmt.Errorf("%w: _type %q", errSpecViolation, statement["_type"])
+	case len(subjects) == 0:
+		return fmt.Errorf("%w: no subject", errSpecViolation)
+	case statement["predicateType"] != "https://slsa.dev/provenance/v1":
+		return fmt.Errorf("%w: predicateType %q", errSpecViolation, statement["predicateType"])
+	}
+
+	if err := checkResourceDescriptors(subjects, "subject"); err != nil {
+		return err
+	}
+
+	// The subjects must have a digest
+	for i, subject := range subjects {
+		if isEmptyObject(subject.(map[string]interface{})["digest"]) {
+			return fmt.Errorf("%w: subject[%d]: no digest", errSpecViolation, i)
+		}
+	}
+
+	predicate := statement["predicate"]
+	if err := messages.check("Provenance", predicate, "predicate"); err != nil {
+		return err
+	}
+
+	provenance, _ := predicate.(map[string]interface{})
+	definition, _ := provenance["buildDefinition"].(map[string]interface{})
+	runDetails, _ := provenance["runDetails"].(map[string]interface{})
+	builder, _ := runDetails["builder"].(map[string]interface{})
+	buildType, _ := definition["buildType"].(string)
+	builderID, _ := builder["id"].(string)
+
+	switch {
+	case len(definition) == 0:
+		return fmt.Errorf("%w: no buildDefinition", errSpecViolation)
+	case isEmptyObject(definition["externalParameters"]):
+		return fmt.Errorf("%w: no externalParameters", errSpecViolation)
+	case len(runDetails) == 0 || len(builder) == 0:
+		return fmt.Errorf("%w: no runDetails.builder", errSpecViolation)
+	}
+
+	if err := checkURI(buildType, "buildType"); err != nil {
+		return err
+	}
+
+	if err := checkURI(builderID, "builder.id"); err != nil {
+		return err
+	}
+
+	for field, descriptors := range map[string]interface{}{
+		"resolvedDependencies": definition["resolvedDependencies"],
+		"builderDependencies":  builder["builderDependencies"],
+		"byproducts":           runDetails["byproducts"],
+	} {
+		if err := checkResourceDescriptors(descriptors, field); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateProvenance checks a statement and returns it as a map
+func validateProvenance(t *testing.T, content []byte) map[string]interface{} {
+	require.NoError(t, checkProvenance(content))
+
+	statement := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(content, &statement))
+
+	return statement
+}
+
+// TestProvenanceSpecExample checks the validation with the example of https://slsa.dev/spec/v1.0/provenance,
+// and with statements breaking the rules of the specifications
+func TestProvenanceSpecExample(t *testing.T) {
+	a := require.New(t)
+
+	messages, err := loadProtoMessages()
+	a.NoError(err)
+	a.Len(messages, 7)
+	a.Equal(map[string]protoField{
+		"_type":         {typeName: "string"},
+		"subject":       {typeName: "in_toto_attestation.v1.ResourceDescriptor", repeated: true},
+		"predicateType": {typeName: "string"},
+		"predicate":     {typeName: "google.protobuf.Struct"},
+	}, messages["Statement"])
+	a.Contains(messages["BuildDefinition"], "resolvedDependencies")
+	a.Contains(messages["ResourceDescriptor"], "downloadLocation")
+
+	content, err := os.ReadFile("testdata/provenance/slsa-v1-example.json")
+	a.NoError(err)
+	a.NoError(checkProvenance(content))
+
+	subject := `[{"digest": {"sha256": "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"}}]`
+	predicate := `"predicate": {"buildDefinition": {"buildType": "https://example.com/build/v1", ` +
+		`"externalParameters": {"version": "1.2.3"}}, "runDetails": {"builder": {"id": "https://example.com/builder"}}}`
+	statement := func(statementType, subject, predicate string) string {
+		return `{"_type": "` + statementType + `", "subject": ` + subject +
+			`, "predicateType": "https://slsa.dev/provenance/v1", ` + predicate + `}`
+	}
+
+	a.NoError(checkProvenance([]byte(statement(inTotoStatementType, subject, predicate))))
+
+	for _, invalid := range []string{
+		statement("https://in-toto.io/Statement/v0.1", subject, predicate),
+		statement(inTotoStatementType, `[]`, predicate),
+		statement(inTotoStatementType, `[{"name": "a"}]`, predicate),
+		statement(inTotoStatementType, `[{"digest": {"sha256": "ab"}}]`, predicate),
+		statement(inTotoStatementType, `[{"digest": {"sha1": "zz"}}]`, predicate),
+		statement(inTotoStatementType, `[{"name": "a", "sha256": "ab"}]`, predicate),
+		statement(inTotoStatementType, subject, predicate+`, "other": 1`),
+		statement(inTotoStatementType, subject,
+			`"predicate": {"buildDefinition": {"buildType": "https://example.com/build/v1", "externalParameters": {}}, `+
+				`"runDetails": {"builder": {"id": "https://example.com/builder"}}}`),
+		statement(inTotoStatementType, subject,
+			`"predicate": {"buildDefinition": {"buildType": "https://example.com/build/v1", "externalParameters": {"a": 1}}}`),
+		statement(inTotoStatementType, subject,
+			`"predicate": {"buildDefinition": {"buildType": "build", "externalParameters": {"a": 1}}, `+
+				`"runDetails": {"builder": {"id": "https://example.com/builder"}}}`),
+		statement(inTotoStatementType, subject,
+			`"predicate": {"buildDefinition": {"buildType": "https://example.com/build/v1", "externalParameters": {"a": 1}}, `+
+				`"runDetails": {"builder": {"id": "https://example.com/builder", "version": {"ci-info": 1}}}}`),
+		statement(inTotoStatementType, subject,
+			`"predicate": {"buildDefinition": {"buildType": "https://example.com/build/v1", "externalParameters": {"a": 1}}, `+
+				`"runDetails": {"builder": {"id": "https://example.com/builder"}, "metadata": {"startedOn": "today"}}}`),
+	} {
+		a.Error(checkProvenance([]byte(invalid)), invalid)
+	}
+}
+
+func TestProvenanceStatement(t *testing.T) {
+	a := require.New(t)
+	bi := &BuildInfo{
+		CIInfoVersion:    "0.9.0",
+		Version:          "1.2.3",
+		GitCommitHash:    "f96a75638b0e1767f969e23f383f4bc75c0e6ba0",
+		GitTag:           "v1.2.3",
+		GitRepositoryURL: "https://github.com/fclairamb/ci-info",
+		CISolution:       "github-actions",
+		CIBuildNumber:    "123",
+		CIBuildURL:       "https://github.com/fclairamb/ci-info/actions/runs/123",
+		BuildDate:        "2022-04-23T23:52:13+02:00",
+	}
+	subjects := []slsaResourceDescriptor{{
+		Name:   "dist/app",
+		Digest: map[string]string{"sha256": "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"},
+	}}
+
+	content, err := json.Marshal(newProvenanceStatement(bi, &ConfigProvenance{}, subjects))
+	a.NoError(err)
+
+	statement := validateProvenance(t, content)
+	predicate := statement["predicate"].(map[string]interface{})
+	definition := predicate["buildDefinition"].(map[string]interface{})
+	runDetails := predicate["runDetails"].(map[string]interface{})
+
+	a.Equal([]interface{}{map[string]interface{}{
+		"uri":    "git+https://github.com/fclairamb/ci-info@refs/tags/v1.2.3",
+		"digest": map[string]interface{}{"gitCommit": "f96a75638b0e1767f969e23f383f4bc75c0e6ba0"},
+	}}, definition["resolvedDependencies"])
+	a.Equal(map[string]interface{}{
+		"repository": "https://github.com/fclairamb/ci-info", "ref": "refs/tags/v1.2.3", "version": "1.2.3",
+	}, definition["externalParameters"])
+	a.Equal(map[string]interface{}{
+		"id": "https://github.com/actions/runner", "version": map[string]interface{}{"ci-info": "0.9.0"},
+	}, runDetails["builder"])
+	a.Equal(map[string]interface{}{
+		"invocationId": "https://github.com/fclairamb/ci-info/actions/runs/123", "startedOn": "2022-04-23T21:52:13Z",
+	}, runDetails["metadata"])
+
+	// A local build without any git info
+	content, err = json.Marshal(newProvenanceStatement(
+		&BuildInfo{Version: "1.2.3-laptop", BuildHost: "laptop", BuildDate: "2022-04-23-2210"}, &ConfigProvenance{}, subjects,
+	))
+	a.NoError(err)
+
+	statement = validateProvenance(t, content)
+	predicate = statement["predicate"].(map[string]interface{})
+	a.Equal(map[string]interface{}{"builder": map[string]interface{}{"id": "urn:ci-info:host:laptop"}}, predicate["runDetails"])
+	a.NotContains(predicate["buildDefinition"], "resolvedDependencies")
+}
+
+func TestMainProvenance(t *testing.T) {
+	a := require.New(t)
+	dir := versionTestdata(t, "1.2.3")
+	configFile := filepath.Join(dir, ".ci-info.json")
+
+	a.NoError(os.MkdirAll(filepath.Join(dir, "dist"), 0750))
+	a.NoError(os.WriteFile(filepath.Join(dir, "dist", "app"), []byte("hello\n"), 0600))
+
+	output, err := captureStdout(t, func() error { return runMain([]string{"-c", configFile, "provenance", "dist/*"}) })
+	a.NoError(err)
+
+	statement := validateProvenance(t, []byte(output))
+	a.Equal([]interface{}{map[string]interface{}{
+		"name":   "dist/app",
+		"digest": map[string]interface{}{"sha256": "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"},
+	}}, statement["subject"])
+
+	_, err = captureStdout(t, func() error { return runMain([]string{"-c", configFile, "provenance", "build/*"}) })
+	a.ErrorIs(err, os.ErrNotExist)
+
+	_, err = captureStdout(t, func() error { return runMain([]string{"-c", configFile, "provenance"}) })
+	a.ErrorIs(err, errNoProvenanceSubject)
+}
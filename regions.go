@@ -0,0 +1,195 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	errRegionNotFound  = errors.New("region not found")
+	errPatternNotFound = errors.New("pattern not found")
+)
+
+const (
+	regionBeginMarker = "ci-info:begin"
+	regionEndMarker   = "ci-info:end"
+)
+
+// regionCommentClosers and regionCommentOpeners surround the markers, depending on the language of the file
+var (
+	regionCommentClosers = []string{"-->", "*/"}
+	regionCommentOpeners = []string{"<!--", "/*", "//", "#", ";", "--", "'", "%"}
+)
+
+// regionMarkerEnd is what can follow a marker, so that the region "version" doesn't match "versionCode"
+const regionMarkerEnd = `(?:[ \t]+|-->|\*/|$)`
+
+var reRegionEnd = regexp.MustCompile(`(?m)` + regexp.QuoteMeta(regionEndMarker) + regionMarkerEnd)
+
+// updateInPlace tells if the template updates parts of an existing file instead of generating it
+func (t *ConfigTemplate) updateInPlace() bool {
+	return len(t.Regions) > 0 || len(t.Replacements) > 0
+}
+
+// updateFile rewrites the regions and the replacements of a file, leaving the rest of it untouched
+func updateFile(config *Config, tplConfig *ConfigTemplate, fileName string, buildInfo *BuildInfo) error {
+	content, err := config.writer().ReadFile(fileName)
+	if err != nil {
+		return fmt.Errorf("could not read file to update: %w", err)
+	}
+
+	updated, err := tplConfig.updateContent(config, string(content), buildInfo)
+	if err != nil {
+		return fmt.Errorf("could not update %s: %w", path.Base(fileName), err)
+	}
+
+	if updated == string(content) {
+		log.Debug("File is up to date", "path", fileName)
+
+		return nil
+	}
+
+	log.Debug("Updating file", "path", fileName)
+
+	return config.writer().WriteFile(fileName, []byte(updated), 0)
+}
+
+func (t *ConfigTemplate) updateContent(config *Config, content string, buildInfo *BuildInfo) (string, error) {
+	for _, name := range sortedKeys(t.Regions) {
+		value, err := t.render(config, t.Regions[name], buildInfo)
+		if err != nil {
+			return "", fmt.Errorf("could not render region %s: %w", name, err)
+		}
+
+		if content, err = replaceRegion(content, name, value); err != nil {
+			return "", err
+		}
+	}
+
+	for _, replacement := range t.Replacements {
+		value, err := t.render(config, replacement.Value, buildInfo)
+		if err != nil {
+			return "", fmt.Errorf("could not render replacement of %s: %w", replacement.Pattern, err)
+		}
+
+		if content, err = replacePattern(content, replacement.Pattern, value); err != nil {
+			return "", err
+		}
+	}
+
+	return content, nil
+}
+
+// replaceRegion replaces the text between the "ci-info:begin <name>" and "ci-info:end" markers. When the markers
+// are on their own lines, the whole lines between them are replaced. Otherwise, only the text between the comments
+// containing the markers is: <!-- ci-info:begin version -->1.2.3<!-- ci-info:end -->
+func replaceRegion(content, name, value string) (string, error) {
+	reBegin := regexp.MustCompile(`(?m)` + regexp.QuoteMeta(regionBeginMarker) + `[ \t]+` + regexp.QuoteMeta(name) +
+		regionMarkerEnd)
+
+	offset := 0
+
+	for {
+		loc := reBegin.FindStringIndex(content[offset:])
+		if loc == nil {
+			if offset == 0 {
+				return "", fmt.Errorf("%w: %s", errRegionNotFound, name)
+			}
+
+			return content, nil
+		}
+
+		start := offset + loc[1]
+		start += closerLength(content[start:])
+
+		// A block region starts on the line after the begin marker
+		block := false
+		if lineEnd := strings.IndexByte(content[start:], '\n'); lineEnd >= 0 && strings.TrimSpace(content[start:start+lineEnd]) == "" {
+			start += lineEnd + 1
+			block = true
+		}
+
+		endMarker := reRegionEnd.FindStringIndex(content[start:])
+		if endMarker == nil {
+			return "", fmt.Errorf("%w: no end marker for %s", errRegionNotFound, name)
+		}
+
+		end := start + endMarker[0]
+		end -= openerLength(content[:end])
+
+		regionValue := value
+
+		// ...and ends at the beginning of the line of the end marker
+		if lineStart := strings.LastIndexByte(content[:end], '\n') + 1; block && lineStart >= start &&
+			strings.TrimSpace(content[lineStart:end]) == "" {
+			end = lineStart
+
+			if regionValue != "" && !strings.HasSuffix(regionValue, "\n") {
+				regionValue += "\n"
+			}
+		}
+
+		content = content[:start] + regionValue + content[end:]
+		offset = start + len(regionValue) + len(regionEndMarker)
+	}
+}
+
+// closerLength returns the length of the comment closer at the beginning of s
+func closerLength(s string) int {
+	for _, closer := range regionCommentClosers {
+		if strings.HasPrefix(s, closer) {
+			return len(closer)
+		}
+	}
+
+	return 0
+}
+
+// openerLength returns the length of the comment opener, and of the spaces following it, at the end of s
+func openerLength(s string) int {
+	trimmed := strings.TrimRight(s, " \t")
+
+	for _, opener := range regionCommentOpeners {
+		if strings.HasSuffix(trimmed, opener) {
+			return len(s) - len(trimmed) + len(opener)
+		}
+	}
+
+	return len(s) - len(trimmed)
+}
+
+// replacePattern replaces the matches of a regular expression. If the expression has groups, only the
+// first group is replaced, so that "version = \"(.*)\"" only replaces the version.
+func replacePattern(content, pattern, value string) (string, error) {
+	re, err := regexp.Compile("(?m)" + pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern %s: %w", pattern, err)
+	}
+
+	matches := re.FindAllStringSubmatchIndex(content, -1)
+	if matches == nil {
+		return "", fmt.Errorf("%w: %s", errPatternNotFound, pattern)
+	}
+
+	var b strings.Builder
+
+	last := 0
+
+	for _, match := range matches {
+		start, end := match[0], match[1]
+		if len(match) > 2 && match[2] >= 0 {
+			start, end = match[2], match[3]
+		}
+
+		b.WriteString(content[last:start])
+		b.WriteString(value)
+		last = end
+	}
+
+	b.WriteString(content[last:])
+
+	return b.String(), nil
+}
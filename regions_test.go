@@ -0,0 +1,129 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaceRegion(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		value    string
+		expected string
+	}{
+		{
+			name:     "inline",
+			content:  "Version: <!-- ci-info:begin version -->0.0.0<!-- ci-info:end --> is out",
+			value:    "1.2.3",
+			expected: "Version: <!-- ci-info:begin version -->1.2.3<!-- ci-info:end --> is out",
+		},
+		{
+			name:     "block",
+			content:  "a\n# ci-info:begin version\nold\nlines\n# ci-info:end\nb\n",
+			value:    "VERSION=1.2.3",
+			expected: "a\n# ci-info:begin version\nVERSION=1.2.3\n# ci-info:end\nb\n",
+		},
+		{
+			name:     "indented block",
+			content:  "{\n  /* ci-info:begin version */\n  old\n  /* ci-info:end */\n}\n",
+			value:    "  \"1.2.3\"\n",
+			expected: "{\n  /* ci-info:begin version */\n  \"1.2.3\"\n  /* ci-info:end */\n}\n",
+		},
+		{
+			name:     "empty block",
+			content:  "// ci-info:begin version\n// ci-info:end\n",
+			value:    "1.2.3",
+			expected: "// ci-info:begin version\n1.2.3\n// ci-info:end\n",
+		},
+		{
+			name:     "multiple",
+			content:  "<!-- ci-info:begin version -->0<!-- ci-info:end --> <!-- ci-info:begin version -->0<!-- ci-info:end -->",
+			value:    "1",
+			expected: "<!-- ci-info:begin version -->1<!-- ci-info:end --> <!-- ci-info:begin version -->1<!-- ci-info:end -->",
+		},
+		{
+			name:     "name prefix",
+			content:  "<!-- ci-info:begin versionCode -->42<!-- ci-info:end -->\n<!-- ci-info:begin version-->0<!-- ci-info:end-->\n",
+			value:    "1.2.3",
+			expected: "<!-- ci-info:begin versionCode -->42<!-- ci-info:end -->\n<!-- ci-info:begin version-->1.2.3<!-- ci-info:end-->\n",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			a := require.New(t)
+
+			result, err := replaceRegion(test.content, "version", test.value)
+			a.NoError(err)
+			a.Equal(test.expected, result)
+
+			// Replacing twice doesn't change anything
+			result, err = replaceRegion(result, "version", test.value)
+			a.NoError(err)
+			a.Equal(test.expected, result)
+		})
+	}
+
+	_, err := replaceRegion("no marker", "version", "1.2.3")
+	require.ErrorIs(t, err, errRegionNotFound)
+
+	_, err = replaceRegion("# ci-info:begin version\n", "version", "1.2.3")
+	require.ErrorIs(t, err, errRegionNotFound)
+
+	_, err = replaceRegion("<!-- ci-info:begin versionCode -->42<!-- ci-info:end -->\n", "version", "1.2.3")
+	require.ErrorIs(t, err, errRegionNotFound)
+}
+
+func TestReplacePattern(t *testing.T) {
+	a := require.New(t)
+
+	result, err := replacePattern("name = \"app\"\nversion = \"0.0.0\"\n", `^version = "(.*)"$`, "1.2.3")
+	a.NoError(err)
+	a.Equal("name = \"app\"\nversion = \"1.2.3\"\n", result)
+
+	result, err = replacePattern("FILEVERSION 0,0,0,0", `\d+,\d+,\d+,\d+`, "1,2,3,0")
+	a.NoError(err)
+	a.Equal("FILEVERSION 1,2,3,0", result)
+
+	_, err = replacePattern("nothing", `version`, "1.2.3")
+	a.ErrorIs(err, errPatternNotFound)
+}
+
+func TestMainRunRegions(t *testing.T) {
+	a := require.New(t)
+	dir := copyTestdata(t, "testdata/regions")
+
+	t.Setenv("REGIONS_VERSION", "v1.4.2")
+
+	a.NoError(runMain([]string{"-c", filepath.Join(dir, ".ci-info.json")}))
+
+	readme, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	a.NoError(err)
+	a.Equal(`# App
+
+Current version: <!-- ci-info:begin version -->1.4.2<!-- ci-info:end -->
+
+## Install
+<!-- ci-info:begin install -->
+`+"```sh\ncurl -L https://example.com/app_1.4.2.tar.gz | tar -x\n```"+`
+<!-- ci-info:end -->
+
+Hand-written content stays as is.
+`, string(readme))
+
+	plist, err := os.ReadFile(filepath.Join(dir, "Info.plist"))
+	a.NoError(err)
+	a.Contains(string(plist), "\t<string>1.4.2</string>\n\t<key>CFBundleName</key>")
+
+	// A second run doesn't change anything
+	a.NoError(runMain([]string{"-c", filepath.Join(dir, ".ci-info.json")}))
+
+	again, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	a.NoError(err)
+	a.Equal(readme, again)
+}
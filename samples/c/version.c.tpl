@@ -2,8 +2,8 @@
 
 build_info_t build_info = {
     .version = "{{ .Version }}",
-    .commit_hash = "{{ .CommitHash }}", 
-    .commit_date = "{{ .CommitDate }}", 
-    .commit_smart = "{{ .CommitSmart }}",
+    .commit_hash = "{{ .GitCommitHash }}",
+    .commit_date = "{{ .GitCommitDate }}",
+    .commit_smart = "{{ .GitSmartRef }}",
     .build_date = "{{ .BuildDate }}",
 };
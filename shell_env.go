@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var errUnknownShell = errors.New("unknown shell")
+
+const defaultShellEnvPrefix = "CI_INFO_"
+
+// shellExporters format an environment variable assignment for a shell
+var shellExporters = map[string]func(name, value string) string{
+	"sh":         func(name, value string) string { return "export " + name + "=" + shellQuote(value) + "\n" },
+	"fish":       func(name, value string) string { return "set -gx " + name + " " + fishQuote(value) + "\n" },
+	"powershell": func(name, value string) string { return "$env:" + name + " = " + powershellQuote(value) + "\n" },
+}
+
+// runEnv prints the statements exporting the build info as environment variables: eval "$(ci-info env)"
+func runEnv(config *Config) error {
+	shellEnv := ConfigShellEnv{}
+	if config.ShellEnv != nil {
+		shellEnv = *config.ShellEnv
+	}
+
+	shell := strings.ToLower(shellEnv.Shell)
+	switch shell {
+	case "", "bash", "zsh":
+		shell = "sh"
+	case "pwsh":
+		shell = "powershell"
+	}
+
+	exporter, ok := shellExporters[shell]
+	if !ok {
+		return fmt.Errorf("%w: %s", errUnknownShell, shellEnv.Shell)
+	}
+
+	prefix := defaultShellEnvPrefix
+	if shellEnv.Prefix != nil {
+		prefix = *shellEnv.Prefix
+	}
+
+	buildInfo, err := generateBuildInfo(config)
+	if err != nil {
+		return err
+	}
+
+	entries, err := buildInfo.entries(shellEnv.Fields, keyCaseScreaming)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(string(exportFlat(entries, "_", func(key, value string) string {
+		return exporter(prefix+key, value)
+	})))
+
+	return nil
+}
+
+// fishQuote quotes a value for fish, in which only backslashes and single quotes are escaped in single quotes
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+// powershellQuote quotes a value for PowerShell, which also considers the typographic single quotes as quotes
+func powershellQuote(s string) string {
+	return "'" + strings.NewReplacer("'", "''", "‘", "‘‘", "’", "’’",
+		"‚", "‚‚", "‛", "‛‛").Replace(s) + "'"
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const trickyShellValue = `it's "$HOME" \n $(rm -rf /) ` + "`id`"
+
+func TestShellQuoting(t *testing.T) {
+	a := require.New(t)
+
+	a.Equal(`'it\'s \\ ok'`, fishQuote(`it's \ ok`))
+	a.Equal(`'it''s ‘‘ok’’'`, powershellQuote(`it's ‘ok’`))
+
+	// The shell reads back the exact value
+	output, err := exec.Command("sh", "-c", shellExporters["sh"]("VALUE", trickyShellValue)+`printf %s "$VALUE"`).Output()
+	a.NoError(err)
+	a.Equal(trickyShellValue, string(output))
+}
+
+func TestMainEnv(t *testing.T) {
+	a := require.New(t)
+	configFile := filepath.Join(versionTestdata(t, "1.2.3"), ".ci-info.json")
+
+	output, err := captureStdout(t, func() error { return runMain([]string{"-c", configFile, "env"}) })
+	a.NoError(err)
+	a.Contains(output, "export CI_INFO_VERSION='1.2.3'\n")
+	a.Contains(output, "export CI_INFO_GIT_HASH='")
+
+	output, err = captureStdout(t, func() error {
+		return runMain([]string{"-c", configFile, "env", "--shell", "fish", "--prefix", "APP_", "--fields", "version,GitSmartRef"})
+	})
+	a.NoError(err)
+	a.Regexp(`^set -gx APP_VERSION '1\.2\.3'\nset -gx APP_GIT_SMART_REF '[^']+'\n$`, output)
+
+	output, err = captureStdout(t, func() error {
+		return runMain([]string{"-c", configFile, "env", "--shell", "pwsh", "--fields", "VersionDeclared"})
+	})
+	a.NoError(err)
+	a.Equal("$env:CI_INFO_VERSION_DECLARED = '1.2.3'\n", output)
+
+	_, err = captureStdout(t, func() error { return runMain([]string{"-c", configFile, "env", "--shell", "cmd"}) })
+	a.ErrorIs(err, errUnknownShell)
+}
@@ -0,0 +1,465 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	errNoSigningKey         = errors.New("no signing key")
+	errUnsupportedKey       = errors.New("unsupported key, expected an ed25519 or ECDSA one")
+	errInvalidSignature     = errors.New("invalid signature")
+	errUnknownSigningFormat = errors.New("unknown signing format, expected sig or dsse")
+	errKeyPassword          = errors.New("could not decrypt the key")
+)
+
+const (
+	signingFormatSig  = "sig"
+	signingFormatDSSE = "dsse"
+
+	// envKeyPrefix designates a key stored in an environment variable, like cosign does: env://SIGNING_KEY
+	envKeyPrefix = "env://"
+
+	dssePayloadType = "application/vnd.ci-info.build+json"
+)
+
+// keyPasswordEnvVars contain the password of the encrypted keys
+var keyPasswordEnvVars = []string{"CI_INFO_KEY_PASSWORD", "COSIGN_PASSWORD"}
+
+// dsseEnvelope is a signed payload, see https://github.com/secure-systems-lab/dsse/blob/master/envelope.md
+type dsseEnvelope struct {
+	PayloadType string          `json:"payloadType"`
+	Payload     string          `json:"payload"`
+	Signatures  []dsseSignature `json:"signatures"`
+}
+
+type dsseSignature struct {
+	KeyID string `json:"keyid,omitempty"`
+	Sig   string `json:"sig"`
+}
+
+// signingFormat returns the format of the signature, a detached one by default
+func (c *ConfigSigning) signingFormat() (string, error) {
+	switch c.Format {
+	case "", signingFormatSig:
+		return signingFormatSig, nil
+	case signingFormatDSSE:
+		return signingFormatDSSE, nil
+	default:
+		return "", fmt.Errorf("%w: %s", errUnknownSigningFormat, c.Format)
+	}
+}
+
+// signBuildInfo writes the signature of the build info file next to it: build.json.sig for a detached
+// signature, build.json.dsse for an envelope containing the build info
+func signBuildInfo(config *Config, buildInfo *BuildInfo, fileName string, format buildInfoFormat) error {
+	if fileName == stdoutPath {
+		log.Warn("The build info printed to the standard output isn't signed")
+
+		return nil
+	}
+
+	signingFormat, err := config.Signing.signingFormat()
+	if err != nil {
+		return err
+	}
+
+	keyContent, err := loadKey(config.Directory, config.Signing.Key)
+	if err != nil {
+		return err
+	}
+
+	signer, err := parsePrivateKey(keyContent)
+	if err != nil {
+		return err
+	}
+
+	content, err := buildInfo.export(fileName, format)
+	if err != nil {
+		return err
+	}
+
+	if signingFormat == signingFormatSig {
+		signature, err := sign(signer, content)
+		if err != nil {
+			return err
+		}
+
+		return config.writer().WriteFile(fileName+".sig", []byte(base64.StdEncoding.EncodeToString(signature)), 0)
+	}
+
+	signature, err := sign(signer, dssePAE(dssePayloadType, content))
+	if err != nil {
+		return err
+	}
+
+	envelope, err := json.MarshalIndent(&dsseEnvelope{
+		PayloadType: dssePayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(content),
+		Signatures:  []dsseSignature{{Sig: base64.StdEncoding.EncodeToString(signature)}},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return config.writer().WriteFile(fileName+".dsse", append(envelope, '\n'), 0)
+}
+
+// runVerify checks the signature of the build info file, or of the file given as argument, and prints
+// the verified fields
+func runVerify(config *Config, args []string) error {
+	if config.Signing == nil {
+		return errNoSigningKey
+	}
+
+	fileName := config.BuildInfoFile
+	if len(args) > 0 {
+		fileName = args[0]
+	}
+
+	if fileName == "" {
+		return fmt.Errorf("%w: build info file", errMissingArgument)
+	}
+
+	content, err := verifyFile(config, config.outputPath(fileName))
+	if err != nil {
+		return err
+	}
+
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(content, &fields); err != nil {
+		// The other formats are printed as they are
+		fmt.Print(string(content))
+
+		return nil
+	}
+
+	printVerifiedFields("", fields)
+
+	return nil
+}
+
+// verifyFile returns the content of a file once its signature is verified
+func verifyFile(config *Config, fileName string) ([]byte, error) {
+	signingFormat, err := config.Signing.signingFormat()
+	if err != nil {
+		return nil, err
+	}
+
+	// The public key can be derived from the private one
+	key := config.Signing.PublicKey
+	if key == "" {
+		key = config.Signing.Key
+	}
+
+	keyContent, err := loadKey(config.Directory, key)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := parsePublicKey(keyContent)
+	if err != nil {
+		return nil, err
+	}
+
+	if signingFormat == signingFormatSig {
+		content, err := os.ReadFile(fileName) //nolint:gosec
+		if err != nil {
+			return nil, err
+		}
+
+		signature, err := readBase64File(fileName + ".sig")
+		if err != nil {
+			return nil, err
+		}
+
+		if !verify(publicKey, content, signature) {
+			return nil, fmt.Errorf("%w for %s", errInvalidSignature, fileName)
+		}
+
+		return content, nil
+	}
+
+	return verifyEnvelope(publicKey, fileName)
+}
+
+// verifyEnvelope returns the payload of the envelope of a file, which must match the file if it exists
+func verifyEnvelope(publicKey crypto.PublicKey, fileName string) ([]byte, error) {
+	envelopeContent, err := os.ReadFile(fileName + ".dsse") //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := &dsseEnvelope{}
+	if err := json.Unmarshal(envelopeContent, envelope); err != nil {
+		return nil, fmt.Errorf("could not parse envelope: %w", err)
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode payload: %w", err)
+	}
+
+	verified := false
+
+	for _, sig := range envelope.Signatures {
+		signature, err := base64.StdEncoding.DecodeString(sig.Sig)
+		if err == nil && verify(publicKey, dssePAE(envelope.PayloadType, payload), signature) {
+			verified = true
+
+			break
+		}
+	}
+
+	if !verified {
+		return nil, fmt.Errorf("%w for %s.dsse", errInvalidSignature, fileName)
+	}
+
+	if content, err := os.ReadFile(fileName); err == nil && string(content) != string(payload) { //nolint:gosec
+		return nil, fmt.Errorf("%w: %s doesn't match its envelope", errInvalidSignature, fileName)
+	}
+
+	return payload, nil
+}
+
+// printVerifiedFields prints the fields as key=value lines, the nested ones being prefixed by their parent key
+func printVerifiedFields(prefix string, fields map[string]interface{}) {
+	for _, key := range sortedKeys(fields) {
+		switch value := fields[key].(type) {
+		case map[string]interface{}:
+			printVerifiedFields(prefix+key+".", value)
+		case string:
+			fmt.Printf("%s%s=%s\n", prefix, key, value)
+		default:
+			encoded, _ := json.Marshal(value)
+			fmt.Printf("%s%s=%s\n", prefix, key, encoded)
+		}
+	}
+}
+
+// dssePAE returns the pre-authentication encoding of a payload, which is what DSSE signs
+func dssePAE(payloadType string, payload []byte) []byte {
+	return []byte("DSSEv1 " + strconv.Itoa(len(payloadType)) + " " + payloadType + " " +
+		strconv.Itoa(len(payload)) + " " + string(payload))
+}
+
+// sign signs a message like cosign does: ECDSA keys sign its sha256 digest
+func sign(signer crypto.Signer, message []byte) ([]byte, error) {
+	switch key := signer.(type) {
+	case ed25519.PrivateKey:
+		return ed25519.Sign(key, message), nil
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256(message)
+
+		return ecdsa.SignASN1(rand.Reader, key, digest[:])
+	default:
+		return nil, errUnsupportedKey
+	}
+}
+
+func verify(publicKey crypto.PublicKey, message, signature []byte) bool {
+	switch key := publicKey.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, message, signature)
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(message)
+
+		return ecdsa.VerifyASN1(key, digest[:], signature)
+	default:
+		return false
+	}
+}
+
+// loadKey returns the content of a key file, relative to the config directory, or of an environment variable
+func loadKey(dir, key string) ([]byte, error) {
+	if key == "" {
+		return nil, errNoSigningKey
+	}
+
+	if strings.HasPrefix(key, envKeyPrefix) {
+		name := strings.TrimPrefix(key, envKeyPrefix)
+
+		value := os.Getenv(name)
+		if value == "" {
+			return nil, fmt.Errorf("%w: %s is empty", errNoSigningKey, name)
+		}
+
+		return []byte(value), nil
+	}
+
+	if !filepath.IsAbs(key) {
+		key = filepath.Join(dir, key)
+	}
+
+	content, err := os.ReadFile(key) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("could not read key: %w", err)
+	}
+
+	return content, nil
+}
+
+func readBase64File(fileName string) ([]byte, error) {
+	content, err := os.ReadFile(fileName) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, fmt.Errorf("could not decode %s: %w", fileName, err)
+	}
+
+	return decoded, nil
+}
+
+// parsePrivateKey parses a PKCS #8, SEC 1, OpenSSH or encrypted cosign private key
+func parsePrivateKey(content []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM block", errUnsupportedKey)
+	}
+
+	var key interface{}
+	var err error
+
+	switch block.Type {
+	case "OPENSSH PRIVATE KEY":
+		key, err = ssh.ParseRawPrivateKey(content)
+
+		var missingPassphrase *ssh.PassphraseMissingError
+		if errors.As(err, &missingPassphrase) {
+			key, err = ssh.ParseRawPrivateKeyWithPassphrase(content, []byte(keyPassword()))
+		}
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "ENCRYPTED SIGSTORE PRIVATE KEY", "ENCRYPTED COSIGN PRIVATE KEY":
+		var der []byte
+		if der, err = decryptCosignKey(block.Bytes, []byte(keyPassword())); err == nil {
+			key, err = x509.ParsePKCS8PrivateKey(der)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnsupportedKey, block.Type)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not parse private key: %w", err)
+	}
+
+	switch k := key.(type) {
+	case *ed25519.PrivateKey:
+		return *k, nil
+	case ed25519.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		return k, nil
+	default:
+		return nil, fmt.Errorf("%w: %T", errUnsupportedKey, key)
+	}
+}
+
+// parsePublicKey parses a PKIX or authorized_keys public key, or derives it from a private key
+func parsePublicKey(content []byte) (crypto.PublicKey, error) {
+	if block, _ := pem.Decode(content); block != nil && block.Type == "PUBLIC KEY" {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse public key: %w", err)
+		}
+
+		return key, nil
+	}
+
+	if sshKey, _, _, _, err := ssh.ParseAuthorizedKey(content); err == nil {
+		if cryptoKey, ok := sshKey.(ssh.CryptoPublicKey); ok {
+			return cryptoKey.CryptoPublicKey(), nil
+		}
+
+		return nil, fmt.Errorf("%w: %s", errUnsupportedKey, sshKey.Type())
+	}
+
+	signer, err := parsePrivateKey(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return signer.Public(), nil
+}
+
+func keyPassword() string {
+	for _, name := range keyPasswordEnvVars {
+		if password := os.Getenv(name); password != "" {
+			return password
+		}
+	}
+
+	return ""
+}
+
+// cosignEncryptedKey is the scrypt and secretbox encryption of the cosign private keys
+type cosignEncryptedKey struct {
+	KDF struct {
+		Name   string `json:"name"`
+		Params struct {
+			N int `json:"N"`
+			R int `json:"r"`
+			P int `json:"p"`
+		} `json:"params"`
+		Salt []byte `json:"salt"`
+	} `json:"kdf"`
+	Cipher struct {
+		Name  string `json:"name"`
+		Nonce []byte `json:"nonce"`
+	} `json:"cipher"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// decryptCosignKey returns the DER content of an encrypted cosign key
+func decryptCosignKey(content, password []byte) ([]byte, error) {
+	encrypted := &cosignEncryptedKey{}
+	if err := json.Unmarshal(content, encrypted); err != nil {
+		return nil, fmt.Errorf("could not parse encrypted key: %w", err)
+	}
+
+	if encrypted.KDF.Name != "scrypt" || encrypted.Cipher.Name != "nacl/secretbox" || len(encrypted.Cipher.Nonce) != 24 {
+		return nil, fmt.Errorf("%w: %s and %s encryption", errUnsupportedKey, encrypted.KDF.Name, encrypted.Cipher.Name)
+	}
+
+	secret, err := scrypt.Key(password, encrypted.KDF.Salt, encrypted.KDF.Params.N, encrypted.KDF.Params.R,
+		encrypted.KDF.Params.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errKeyPassword, err)
+	}
+
+	var nonce [24]byte
+
+	var secretKey [32]byte
+
+	copy(nonce[:], encrypted.Cipher.Nonce)
+	copy(secretKey[:], secret)
+
+	der, ok := secretbox.Open(nil, encrypted.Ciphertext, &nonce, &secretKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: wrong password", errKeyPassword)
+	}
+
+	return der, nil
+}
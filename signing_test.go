@@ -0,0 +1,208 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/ssh"
+)
+
+// encryptCosignKey encrypts a private key the way "cosign generate-key-pair" does, with a cheaper scrypt
+func encryptCosignKey(t *testing.T, der []byte, password string) []byte {
+	encrypted := &cosignEncryptedKey{}
+	encrypted.KDF.Name = "scrypt"
+	encrypted.KDF.Params.N, encrypted.KDF.Params.R, encrypted.KDF.Params.P = 1024, 8, 1
+	encrypted.KDF.Salt = []byte("0123456789abcdef0123456789abcdef")
+	encrypted.Cipher.Name = "nacl/secretbox"
+	encrypted.Cipher.Nonce = []byte("0123456789abcdef01234567")
+
+	secret, err := scrypt.Key([]byte(password), encrypted.KDF.Salt, 1024, 8, 1, 32)
+	require.NoError(t, err)
+
+	var nonce [24]byte
+
+	var secretKey [32]byte
+
+	copy(nonce[:], encrypted.Cipher.Nonce)
+	copy(secretKey[:], secret)
+
+	encrypted.Ciphertext = secretbox.Seal(nil, der, &nonce, &secretKey)
+
+	content, err := json.Marshal(encrypted)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED SIGSTORE PRIVATE KEY", Bytes: content})
+}
+
+func TestSigningKeys(t *testing.T) {
+	a := require.New(t)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	a.NoError(err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	a.NoError(err)
+
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	a.NoError(err)
+
+	ecDER, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	a.NoError(err)
+
+	ecPublicDER, err := x509.MarshalPKIXPublicKey(ecKey.Public())
+	a.NoError(err)
+
+	sshBlock, err := ssh.MarshalPrivateKey(edKey, "")
+	a.NoError(err)
+
+	sshPublicKey, err := ssh.NewPublicKey(edKey.Public())
+	a.NoError(err)
+
+	t.Setenv("COSIGN_PASSWORD", "secret")
+
+	for name, keys := range map[string][2][]byte{
+		"pkcs8 ed25519": {pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: edDER}), ssh.MarshalAuthorizedKey(sshPublicKey)},
+		"openssh":       {pem.EncodeToMemory(sshBlock), nil},
+		"cosign":        {encryptCosignKey(t, ecDER, "secret"), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: ecPublicDER})},
+	} {
+		signer, err := parsePrivateKey(keys[0])
+		a.NoError(err, name)
+
+		// The public key is derived from the private one if not given
+		publicKeyContent := keys[1]
+		if publicKeyContent == nil {
+			publicKeyContent = keys[0]
+		}
+
+		publicKey, err := parsePublicKey(publicKeyContent)
+		a.NoError(err, name)
+
+		signature, err := sign(signer, []byte("message"))
+		a.NoError(err, name)
+		a.True(verify(publicKey, []byte("message"), signature), name)
+		a.False(verify(publicKey, []byte("messages"), signature), name)
+	}
+
+	t.Setenv("COSIGN_PASSWORD", "wrong")
+
+	_, err = parsePrivateKey(encryptCosignKey(t, ecDER, "secret"))
+	a.ErrorIs(err, errKeyPassword)
+
+	_, err = parsePrivateKey([]byte("not a key"))
+	a.ErrorIs(err, errUnsupportedKey)
+}
+
+func TestLoadKey(t *testing.T) {
+	a := require.New(t)
+	dir := t.TempDir()
+
+	a.NoError(os.WriteFile(filepath.Join(dir, "signing.key"), []byte("file key"), 0600))
+	t.Setenv("SIGNING_KEY", "env key")
+
+	content, err := loadKey(dir, "signing.key")
+	a.NoError(err)
+	a.Equal("file key", string(content))
+
+	content, err = loadKey(dir, "env://SIGNING_KEY")
+	a.NoError(err)
+	a.Equal("env key", string(content))
+
+	_, err = loadKey(dir, "env://MISSING_SIGNING_KEY")
+	a.ErrorIs(err, errNoSigningKey)
+
+	_, err = loadKey(dir, "missing.key")
+	a.ErrorIs(err, os.ErrNotExist)
+}
+
+func TestMainSignAndVerify(t *testing.T) {
+	a := require.New(t)
+	dir := versionTestdata(t, "1.2.3")
+	configFile := filepath.Join(dir, ".ci-info.json")
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	a.NoError(err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	a.NoError(err)
+
+	t.Setenv("SIGNING_KEY", string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})))
+
+	_, err = captureStdout(t, func() error {
+		return runMain([]string{"-c", configFile, "-b", "build.json", "-key", "env://SIGNING_KEY"})
+	})
+	a.NoError(err)
+	a.FileExists(filepath.Join(dir, "build.json.sig"))
+
+	output, err := captureStdout(t, func() error {
+		return runMain([]string{"-c", configFile, "-key", "env://SIGNING_KEY", "verify", "build.json"})
+	})
+	a.NoError(err)
+	a.Contains(output, "version=1.2.3\n")
+
+	// Any change invalidates the signature
+	content, err := os.ReadFile(filepath.Join(dir, "build.json")) //nolint:gosec
+	a.NoError(err)
+	a.NoError(os.WriteFile(filepath.Join(dir, "build.json"), append(content, ' '), 0600))
+
+	_, err = captureStdout(t, func() error {
+		return runMain([]string{"-c", configFile, "-key", "env://SIGNING_KEY", "verify", "build.json"})
+	})
+	a.ErrorIs(err, errInvalidSignature)
+}
+
+func TestSignDSSE(t *testing.T) {
+	a := require.New(t)
+	dir := t.TempDir()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	a.NoError(err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	a.NoError(err)
+	a.NoError(os.WriteFile(filepath.Join(dir, "signing.key"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+
+	config := &Config{Directory: dir, Signing: &ConfigSigning{Key: "signing.key", Format: signingFormatDSSE}}
+	bi := &BuildInfo{Version: "1.2.3", Custom: map[string]string{"vendor": "Acme"}}
+	fileName := filepath.Join(dir, "build.json")
+
+	a.NoError(bi.save(diskOutput{}, fileName, buildInfoFormat{}))
+	a.NoError(signBuildInfo(config, bi, fileName, buildInfoFormat{}))
+
+	envelope := &dsseEnvelope{}
+	content, err := os.ReadFile(fileName + ".dsse") //nolint:gosec
+	a.NoError(err)
+	a.NoError(json.Unmarshal(content, envelope))
+	a.Equal(dssePayloadType, envelope.PayloadType)
+
+	payload, err := verifyFile(config, fileName)
+	a.NoError(err)
+	a.Contains(string(payload), "\"version\": \"1.2.3\"")
+
+	output, err := captureStdout(t, func() error { return runVerify(config, []string{"build.json"}) })
+	a.NoError(err)
+	a.Contains(output, "custom.vendor=Acme\nversion=1.2.3\n")
+
+	// The envelope can be used without the build info file, but must match it
+	a.NoError(os.WriteFile(fileName, []byte("{}"), 0600))
+	_, err = verifyFile(config, fileName)
+	a.ErrorIs(err, errInvalidSignature)
+
+	a.NoError(os.Remove(fileName))
+	_, err = verifyFile(config, fileName)
+	a.NoError(err)
+
+	config.Signing.Format = "pgp"
+	_, err = verifyFile(config, fileName)
+	a.ErrorIs(err, errUnknownSigningFormat)
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+var (
+	errInvalidMode       = errors.New("invalid file mode")
+	errInvalidDelims     = errors.New("invalid delimiters, expected a left and a right one")
+	errInvalidMissingKey = errors.New("invalid missingkey, expected default, zero or error")
+)
+
+func renderTemplate(templateString string, buildInfo *BuildInfo) (string, error) {
+	return (&ConfigTemplate{}).render(&Config{}, templateString, buildInfo)
+}
+
+// saveTemplates generates the output files of the templates
+func saveTemplates(config *Config, buildInfo *BuildInfo) error {
+	for _, template := range config.Templates {
+		templates := []*ConfigTemplate{template}
+
+		if template.InputFiles != "" {
+			var err error
+			if templates, err = template.expandInputFiles(config.Directory); err != nil {
+				return err
+			}
+		}
+
+		for _, tpl := range templates {
+			outputFile := tpl.outputFileName()
+			if outputFile == "" {
+				continue
+			}
+
+			if tpl.updateInPlace() {
+				if err := updateFile(config, tpl, config.outputPath(outputFile), buildInfo); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			templateString, err := tpl.load(config.Directory)
+			if err != nil {
+				return err
+			}
+
+			if err := applyTemplate(config, tpl, templateString, config.outputPath(outputFile), buildInfo); err != nil {
+				return fmt.Errorf("failed to apply template for %s: %w", outputFile, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// expandInputFiles returns a template per file matching the input files, the matching files being
+// rendered to the output directory with the same relative path and without their ".tpl" suffix
+func (t *ConfigTemplate) expandInputFiles(dir string) ([]*ConfigTemplate, error) {
+	matches, err := expandGlob(dir, t.InputFiles)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input files %s: %w", t.InputFiles, err)
+	}
+
+	base := globBase(t.InputFiles)
+	outputDir := strings.TrimSuffix(strings.TrimSuffix(t.OutputFile, "**"), "/")
+	templates := make([]*ConfigTemplate, 0, len(matches))
+
+	for _, match := range matches {
+		if !isFile(path.Join(dir, match)) {
+			continue
+		}
+
+		rel := strings.TrimPrefix(strings.TrimPrefix(match, base), "/")
+
+		tpl := *t
+		tpl.InputFiles = ""
+		tpl.InputFile = match
+		tpl.OutputFile = path.Join(outputDir, strings.TrimSuffix(rel, ".tpl"))
+		templates = append(templates, &tpl)
+	}
+
+	return templates, nil
+}
+
+// loadPartials returns the content of the partials of the config and of the template, by name.
+// The name of a partial is its file name without the ".tpl" extension.
+func (t *ConfigTemplate) loadPartials(config *Config) (map[string]string, error) {
+	partials := map[string]string{}
+
+	for _, pattern := range append(append([]string{}, config.Partials...), t.Partials...) {
+		matches, err := expandGlob(config.Directory, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid partials %s: %w", pattern, err)
+		}
+
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no partial found for %s: %w", pattern, os.ErrNotExist)
+		}
+
+		for _, match := range matches {
+			content, err := os.ReadFile(path.Join(config.Directory, match)) //nolint:gosec
+			if err != nil {
+				return nil, fmt.Errorf("could not read partial: %w", err)
+			}
+
+			partials[strings.TrimSuffix(path.Base(match), ".tpl")] = string(content)
+		}
+	}
+
+	return partials, nil
+}
+
+// load returns the content of the template, whether it's inline, builtin or from a file
+func (t *ConfigTemplate) load(dir string) (string, error) {
+	switch {
+	case t.InputContent != "":
+		return t.InputContent, nil
+	case t.Builtin != "":
+		builtin, err := getBuiltinTemplate(t.Builtin)
+		if err != nil {
+			return "", err
+		}
+
+		return builtin.content()
+	default:
+		log.Debug("Loading template", "path", t.InputFile)
+
+		content, _, err := loadPathAsContent(t.InputFile, dir)
+		if err != nil {
+			return "", fmt.Errorf("failed to load template file from %s: %w", t.InputFile, err)
+		}
+
+		return string(content), nil
+	}
+}
+
+// outputFileName returns the output file, which defaults to the one of the builtin template
+func (t *ConfigTemplate) outputFileName() string {
+	if t.OutputFile == "" && t.Builtin != "" {
+		if builtin, err := getBuiltinTemplate(t.Builtin); err == nil {
+			return builtin.OutputFile
+		}
+	}
+
+	return t.OutputFile
+}
+
+// language returns the language of the template, which defaults to the one of the builtin template
+func (t *ConfigTemplate) language() string {
+	if t.Language == "" && t.Builtin != "" {
+		if builtin, err := getBuiltinTemplate(t.Builtin); err == nil {
+			return builtin.Language
+		}
+	}
+
+	return t.Language
+}
+
+// fileMode returns the mode of the output file, zero meaning the default one
+func (t *ConfigTemplate) fileMode() (os.FileMode, error) {
+	if t.Mode == "" {
+		return 0, nil
+	}
+
+	mode, err := strconv.ParseUint(t.Mode, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("%w: %s", errInvalidMode, t.Mode)
+	}
+
+	return os.FileMode(mode), nil
+}
+
+// option returns the value of an option of the template: {{ option "package" "main" }}
+func (t *ConfigTemplate) option(name, def string) string {
+	if value, ok := t.Options[name]; ok {
+		return value
+	}
+
+	return def
+}
+
+// parse parses the template content and the partials with the options of the template config
+func (t *ConfigTemplate) parse(config *Config, templateString string, buildInfo *BuildInfo) (*template.Template, error) {
+	tpl := template.New("").
+		Funcs(templateFuncs()).
+		Funcs(escaperFuncs()).
+		Funcs(template.FuncMap{"option": t.option, "env": buildInfo.env})
+
+	if len(t.Delims) > 0 {
+		if len(t.Delims) != 2 || t.Delims[0] == "" || t.Delims[1] == "" {
+			return nil, fmt.Errorf("%w: %v", errInvalidDelims, t.Delims)
+		}
+
+		tpl.Delims(t.Delims[0], t.Delims[1])
+	}
+
+	switch t.MissingKey {
+	case "":
+	case "default", "invalid", "zero", "error":
+		tpl.Option("missingkey=" + t.MissingKey)
+	default:
+		return nil, fmt.Errorf("%w: %s", errInvalidMissingKey, t.MissingKey)
+	}
+
+	partials, err := t.loadPartials(config)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, content := range partials {
+		if _, err := tpl.New(name).Parse(content); err != nil {
+			return nil, fmt.Errorf("could not parse partial %s: %w", name, err)
+		}
+	}
+
+	if _, err := tpl.Parse(templateString); err != nil {
+		return nil, fmt.Errorf("could not parse template: %w", err)
+	}
+
+	if t.Strict {
+		if err := checkTemplateFields(tpl); err != nil {
+			return nil, err
+		}
+	}
+
+	if language := t.language(); language != "" {
+		if err := escapeTemplate(tpl, language); err != nil {
+			return nil, err
+		}
+	}
+
+	return tpl, nil
+}
+
+func (t *ConfigTemplate) render(config *Config, templateString string, buildInfo *BuildInfo) (string, error) {
+	var buffer bytes.Buffer
+
+	buildInfo, err := buildInfo.withVariables(t.Variables)
+	if err != nil {
+		return "", err
+	}
+
+	if tpl, err := t.parse(config, templateString, buildInfo); err == nil {
+		if errExec := tpl.Execute(&buffer, buildInfo); errExec != nil {
+			return "", fmt.Errorf("could not execute template: %w", errExec)
+		}
+	} else {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+func applyTemplate(config *Config, tplConfig *ConfigTemplate, templateString string, outputFile string, buildInfo *BuildInfo) error {
+	content, err := tplConfig.render(config, templateString, buildInfo)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Saving formatted file", "path", outputFile)
+
+	mode, err := tplConfig.fileMode()
+	if err != nil {
+		return err
+	}
+
+	if err := config.writer().WriteFile(outputFile, []byte(content), mode); err != nil {
+		return fmt.Errorf("could not write output file: %w", err)
+	}
+
+	return nil
+}
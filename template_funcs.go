@@ -0,0 +1,401 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	errInvalidDate       = errors.New("invalid date")
+	errInvalidConstraint = errors.New("invalid version constraint")
+)
+
+// dateLayouts are the layouts accepted by the date functions, the first one being the one of the build info
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05 -0700",
+	timeFormat,
+	"2006-01-02",
+}
+
+// templateFuncs returns the functions available in the templates. Their last argument is the
+// value they apply to, so that they can be used in pipelines: {{ .GitBranch | upper }}
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// Strings
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"title":      titleCase,
+		"trim":       strings.TrimSpace,
+		"trimAll":    func(cutset, s string) string { return strings.Trim(s, cutset) },
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old, replacement, s string) string { return strings.ReplaceAll(s, old, replacement) },
+		"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":       func(sep string, list []string) string { return strings.Join(list, sep) },
+		"repeat":     func(count int, s string) string { return strings.Repeat(s, count) },
+		"trunc":      truncate,
+		"substr":     substring,
+		"indent":     indent,
+		"nindent":    func(spaces int, s string) string { return "\n" + indent(spaces, s) },
+		"snakecase":  func(s string) string { return joinWords(s, "_", strings.ToLower) },
+		"kebabcase":  func(s string) string { return joinWords(s, "-", strings.ToLower) },
+		"camelcase":  camelCase,
+
+		// Values
+		"default":  defaultValue,
+		"coalesce": coalesce,
+		"empty":    isEmpty,
+
+		// Quoting and encoding
+		"quote":  strconv.Quote,
+		"squote": shellQuote,
+		"json":   toJSON,
+		"toYaml": toYAML,
+
+		// Regular expressions
+		"regexMatch":   regexMatch,
+		"regexFind":    regexFind,
+		"regexReplace": regexReplace,
+
+		// Dates
+		"now":        time.Now,
+		"date":       formatDate,
+		"dateFormat": formatDateStrftime,
+		"unixEpoch":  unixEpoch,
+
+		// Versions
+		"semver":        parseVersion,
+		"semverCompare": semverCompare,
+		"debVersion":    debianVersion,
+		"rpmVersion":    rpmVersion,
+	}
+}
+
+func titleCase(s string) string {
+	previous := ' '
+
+	return strings.Map(func(r rune) rune {
+		defer func() { previous = r }()
+
+		if unicode.IsSpace(previous) || previous == '-' || previous == '_' {
+			return unicode.ToTitle(r)
+		}
+
+		return r
+	}, s)
+}
+
+// truncate keeps the first length characters, or the last ones if length is negative
+func truncate(length int, s string) string {
+	runes := []rune(s)
+
+	switch {
+	case length >= 0 && length < len(runes):
+		return string(runes[:length])
+	case length < 0 && -length < len(runes):
+		return string(runes[len(runes)+length:])
+	default:
+		return s
+	}
+}
+
+func substring(start, end int, s string) string {
+	runes := []rune(s)
+
+	if start < 0 {
+		start = 0
+	}
+
+	if end < 0 || end > len(runes) {
+		end = len(runes)
+	}
+
+	if start > end {
+		return ""
+	}
+
+	return string(runes[start:end])
+}
+
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+// splitWords splits identifiers like "fooBar", "foo-bar" or "foo_bar" into words
+func splitWords(s string) []string {
+	var words []string
+
+	var current []rune
+
+	runes := []rune(s)
+
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			if len(current) > 0 {
+				words = append(words, string(current))
+				current = nil
+			}
+
+			continue
+		case unicode.IsUpper(r) && len(current) > 0 &&
+			(unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			words = append(words, string(current))
+			current = nil
+		}
+
+		current = append(current, r)
+	}
+
+	if len(current) > 0 {
+		words = append(words, string(current))
+	}
+
+	return words
+}
+
+func joinWords(s, sep string, transform func(string) string) string {
+	words := splitWords(s)
+
+	for i, word := range words {
+		words[i] = transform(word)
+	}
+
+	return strings.Join(words, sep)
+}
+
+func camelCase(s string) string {
+	words := splitWords(s)
+
+	for i, word := range words {
+		word = strings.ToLower(word)
+
+		if i > 0 {
+			word = titleCase(word)
+		}
+
+		words[i] = word
+	}
+
+	return strings.Join(words, "")
+}
+
+// isEmpty tells if a value is the zero value of its type, or an empty collection
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+func defaultValue(def interface{}, value ...interface{}) interface{} {
+	if len(value) == 0 || isEmpty(value[0]) {
+		return def
+	}
+
+	return value[0]
+}
+
+func coalesce(values ...interface{}) interface{} {
+	for _, value := range values {
+		if !isEmpty(value) {
+			return value
+		}
+	}
+
+	return nil
+}
+
+func toJSON(value interface{}) (string, error) {
+	b, err := json.Marshal(value)
+
+	return string(b), err
+}
+
+func toYAML(value interface{}) (string, error) {
+	b, err := yaml.Marshal(value)
+
+	return strings.TrimSuffix(string(b), "\n"), err
+}
+
+func regexMatch(pattern, s string) (bool, error) {
+	return regexp.MatchString(pattern, s)
+}
+
+func regexFind(pattern, s string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	return re.FindString(s), nil
+}
+
+func regexReplace(pattern, replacement, s string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	return re.ReplaceAllString(s, replacement), nil
+}
+
+// parseDate accepts a time or a date string as found in the build info
+func parseDate(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case *time.Time:
+		return *v, nil
+	case string:
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: %v", errInvalidDate, value)
+}
+
+// formatDate formats a date with a go layout: {{ .BuildDate | date "2006-01-02" }}
+func formatDate(layout string, value interface{}) (string, error) {
+	t, err := parseDate(value)
+	if err != nil {
+		return "", err
+	}
+
+	return t.Format(layout), nil
+}
+
+// strftimeLayouts maps the strftime directives to go layouts
+var strftimeLayouts = map[byte]string{
+	'Y': "2006",
+	'y': "06",
+	'm': "01",
+	'd': "02",
+	'e': "_2",
+	'H': "15",
+	'I': "03",
+	'M': "04",
+	'S': "05",
+	'p': "PM",
+	'b': "Jan",
+	'B': "January",
+	'a': "Mon",
+	'A': "Monday",
+	'z': "-0700",
+	'Z': "MST",
+	'F': "2006-01-02",
+	'T': "15:04:05",
+}
+
+// formatDateStrftime formats a date with a strftime format: {{ .BuildDate | dateFormat "%Y%m%d" }}
+func formatDateStrftime(format string, value interface{}) (string, error) {
+	t, err := parseDate(value)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			b.WriteByte(format[i])
+
+			continue
+		}
+
+		i++
+
+		switch directive := format[i]; directive {
+		case '%':
+			b.WriteByte('%')
+		case 'j':
+			fmt.Fprintf(&b, "%03d", t.YearDay())
+		case 's':
+			b.WriteString(strconv.FormatInt(t.Unix(), 10))
+		default:
+			if layout, ok := strftimeLayouts[directive]; ok {
+				b.WriteString(t.Format(layout))
+			} else {
+				b.WriteByte('%')
+				b.WriteByte(directive)
+			}
+		}
+	}
+
+	return b.String(), nil
+}
+
+func unixEpoch(value interface{}) (int64, error) {
+	t, err := parseDate(value)
+
+	return t.Unix(), err
+}
+
+// semverCompare checks a version against a constraint: {{ if semverCompare ">=2.0.0" .VersionDeclared }}
+func semverCompare(constraint, version string) (bool, error) {
+	constraint = strings.TrimSpace(constraint)
+
+	var operator string
+
+	for _, op := range []string{">=", "<=", "!=", "==", "=", ">", "<"} {
+		if strings.HasPrefix(constraint, op) {
+			operator = op
+
+			break
+		}
+	}
+
+	expected, err := parseVersion(strings.TrimSpace(strings.TrimPrefix(constraint, operator)))
+	if err != nil {
+		return false, fmt.Errorf("%w: %s", errInvalidConstraint, constraint)
+	}
+
+	actual, err := parseVersion(version)
+	if err != nil {
+		return false, err
+	}
+
+	c := actual.compare(expected)
+
+	switch operator {
+	case ">=":
+		return c >= 0, nil
+	case "<=":
+		return c <= 0, nil
+	case "!=":
+		return c != 0, nil
+	case ">":
+		return c > 0, nil
+	case "<":
+		return c < 0, nil
+	default:
+		return c == 0, nil
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	t.Setenv("CI_INFO_TEST_ENV", "from-env")
+	t.Setenv("CI_INFO_TEST_SECRET", "secret")
+
+	bi := &BuildInfo{
+		Env:             map[string]string{"CI_INFO_TEST_ENV": "from-env"},
+		Version:         "1.2.3-feature-cool-one-a6850c9",
+		VersionDeclared: "1.2.3",
+		GitCommitHash:   "a6850c90c8d3c81377cee5701f79dfbbd6e5a756",
+		GitBranch:       "feature/Cool-One",
+		GitCommitDate:   "2022-09-18T01:27:36+02:00",
+		BuildDate:       "2022-09-18T10:49:46Z",
+		BuildUser:       "runner",
+	}
+
+	for _, test := range []struct {
+		template string
+		expected string
+	}{
+		{`{{ .GitBranch | upper }}`, "FEATURE/COOL-ONE"},
+		{`{{ .GitBranch | lower }}`, "feature/cool-one"},
+		{`{{ "hello big world" | title }}`, "Hello Big World"},
+		{`{{ "  spaced  " | trim }}`, "spaced"},
+		{`{{ "--x--" | trimAll "-" }}`, "x"},
+		{`{{ .GitBranch | trimPrefix "feature/" }}`, "Cool-One"},
+		{`{{ "build.go.tpl" | trimSuffix ".tpl" }}`, "build.go"},
+		{`{{ .GitBranch | replace "/" "_" }}`, "feature_Cool-One"},
+		{`{{ if .GitBranch | hasPrefix "feature/" }}feature{{ end }}`, "feature"},
+		{`{{ if .GitBranch | hasSuffix "One" }}one{{ end }}`, "one"},
+		{`{{ if .GitBranch | contains "Cool" }}cool{{ end }}`, "cool"},
+		{`{{ .GitBranch | split "/" | join "+" }}`, "feature+Cool-One"},
+		{`{{ "ab" | repeat 3 }}`, "ababab"},
+		{`{{ .GitCommitHash | trunc 10 }}`, "a6850c90c8"},
+		{`{{ .GitCommitHash | trunc -4 }}`, "a756"},
+		{`{{ .GitCommitHash | substr 2 6 }}`, "850c"},
+		{`{{ "a\nb" | indent 2 }}`, "  a\n  b"},
+		{`{{ "a" | nindent 2 }}`, "\n  a"},
+		{`{{ "GitCommitHash" | snakecase }}`, "git_commit_hash"},
+		{`{{ "GitCommitHash" | kebabcase }}`, "git-commit-hash"},
+		{`{{ "git_commit_hash" | camelcase }}`, "gitCommitHash"},
+		{`{{ "CIBuildNumber" | snakecase }}`, "ci_build_number"},
+		{`{{ .CIBuildNumber | default "local" }}`, "local"},
+		{`{{ .BuildUser | default "nobody" }}`, "runner"},
+		{`{{ coalesce .GitTag .GitBranch }}`, "feature/Cool-One"},
+		{`{{ if empty .GitTag }}no tag{{ end }}`, "no tag"},
+		{`{{ env "CI_INFO_TEST_ENV" }}`, "from-env"},
+		{`{{ env "CI_INFO_TEST_SECRET" }}`, ""},
+		{`{{ "say \"hi\"" | quote }}`, `"say \"hi\""`},
+		{`{{ .BuildUser | squote }}`, `'runner'`},
+		{`{{ "it's" | squote }}`, `'it'\''s'`},
+		{`{{ .GitBranch | json }}`, `"feature/Cool-One"`},
+		{`{{ split "." "1.2" | toYaml }}`, "- \"1\"\n- \"2\""},
+		{`{{ .GitBranch | regexReplace "[^a-zA-Z0-9]+" "_" }}`, "feature_Cool_One"},
+		{`{{ .Version | regexFind "[0-9]+\\.[0-9]+" }}`, "1.2"},
+		{`{{ if .Version | regexMatch "^1\\." }}v1{{ end }}`, "v1"},
+		{`{{ .BuildDate | date "2006-01-02" }}`, "2022-09-18"},
+		{`{{ .GitCommitDate | date "15:04 -0700" }}`, "01:27 +0200"},
+		{`{{ .BuildDate | dateFormat "%Y%m%d-%H%M%S %j %%" }}`, "20220918-104946 261 %"},
+		{`{{ .BuildDate | unixEpoch }}`, "1663498186"},
+		{`{{ (semver .VersionDeclared).Minor }}`, "2"},
+		{`{{ (semver .Version).Prerelease }}`, "feature-cool-one-a6850c9"},
+		{`{{ if semverCompare ">=1.2.0" .VersionDeclared }}recent{{ end }}`, "recent"},
+		{`{{ if semverCompare "<1.2.0" .VersionDeclared }}old{{ end }}`, ""},
+	} {
+		t.Run(test.template, func(t *testing.T) {
+			a := require.New(t)
+			content, err := renderTemplate(test.template, bi)
+			a.NoError(err)
+			a.Equal(test.expected, content)
+		})
+	}
+}
+
+func TestTemplateFuncsErrors(t *testing.T) {
+	bi := &BuildInfo{BuildDate: "yesterday", Version: "main"}
+
+	for _, template := range []string{
+		`{{ .BuildDate | date "2006" }}`,
+		`{{ .Version | regexReplace "(" "" }}`,
+		`{{ semver .Version }}`,
+		`{{ semverCompare "~>1" "1.0.0" }}`,
+	} {
+		t.Run(template, func(t *testing.T) {
+			_, err := renderTemplate(template, bi)
+			require.Error(t, err)
+		})
+	}
+}
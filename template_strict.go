@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// checkTemplateFields fails if the template references a field the build info doesn't have. Only the fields
+// of the build info itself are checked: the ones of "$" and the ones of "." outside of "with" and "range". The
+// partials and the "define" blocks are checked when they are called with the build info.
+func checkTemplateFields(tpl *template.Template) error {
+	if tpl.Tree == nil || tpl.Tree.Root == nil {
+		return nil
+	}
+
+	checker := &fieldChecker{tpl: tpl, tree: tpl.Tree, checked: map[string]bool{tpl.Name(): true}}
+
+	return checker.checkNode(tpl.Tree.Root, true)
+}
+
+// fieldChecker checks the fields of a template tree, "$" being the build info in the main template and in the
+// templates it calls with the build info
+type fieldChecker struct {
+	tpl  *template.Template
+	tree *parse.Tree
+
+	// checked contains the templates that were already checked, so that the recursive ones are only checked once
+	checked map[string]bool
+}
+
+func (c *fieldChecker) checkNode(node parse.Node, dotIsBuildInfo bool) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+
+		for _, child := range n.Nodes {
+			if err := c.checkNode(child, dotIsBuildInfo); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return c.checkPipe(n.Pipe, dotIsBuildInfo)
+	case *parse.TemplateNode:
+		if err := c.checkPipe(n.Pipe, dotIsBuildInfo); err != nil {
+			return err
+		}
+
+		if isBuildInfoPipe(n.Pipe, dotIsBuildInfo) {
+			return c.checkCalledTemplate(n.Name)
+		}
+	case *parse.IfNode:
+		return c.checkBranch(&n.BranchNode, dotIsBuildInfo, dotIsBuildInfo)
+	case *parse.RangeNode:
+		return c.checkBranch(&n.BranchNode, dotIsBuildInfo, false)
+	case *parse.WithNode:
+		return c.checkBranch(&n.BranchNode, dotIsBuildInfo, false)
+	}
+
+	return nil
+}
+
+// isBuildInfoPipe tells if the argument of a template call is the build info: "." or "$"
+func isBuildInfoPipe(pipe *parse.PipeNode, dotIsBuildInfo bool) bool {
+	if pipe == nil || len(pipe.Decl) > 0 || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.DotNode:
+		return dotIsBuildInfo
+	case *parse.VariableNode:
+		return len(arg.Ident) == 1 && arg.Ident[0] == "$"
+	}
+
+	return false
+}
+
+// checkCalledTemplate checks a partial or a "define" block called with the build info
+func (c *fieldChecker) checkCalledTemplate(name string) error {
+	called := c.tpl.Lookup(name)
+	if called == nil || called.Tree == nil || c.checked[name] {
+		return nil
+	}
+
+	c.checked[name] = true
+
+	checker := &fieldChecker{tpl: c.tpl, tree: called.Tree, checked: c.checked}
+
+	return checker.checkNode(called.Tree.Root, true)
+}
+
+// checkBranch checks a branch, dot being changed in its list by "with" and "range"
+func (c *fieldChecker) checkBranch(branch *parse.BranchNode, dotIsBuildInfo, listDotIsBuildInfo bool) error {
+	if err := c.checkPipe(branch.Pipe, dotIsBuildInfo); err != nil {
+		return err
+	}
+
+	if err := c.checkNode(branch.List, listDotIsBuildInfo); err != nil {
+		return err
+	}
+
+	return c.checkNode(branch.ElseList, dotIsBuildInfo)
+}
+
+func (c *fieldChecker) checkPipe(pipe *parse.PipeNode, dotIsBuildInfo bool) error {
+	if pipe == nil {
+		return nil
+	}
+
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			var idents []string
+
+			switch a := arg.(type) {
+			case *parse.FieldNode:
+				if dotIsBuildInfo {
+					idents = a.Ident
+				}
+			case *parse.VariableNode:
+				if a.Ident[0] == "$" {
+					idents = a.Ident[1:]
+				}
+			case *parse.PipeNode:
+				if err := c.checkPipe(a, dotIsBuildInfo); err != nil {
+					return err
+				}
+			}
+
+			if err := checkFieldChain(reflect.TypeOf(&BuildInfo{}), idents); err != nil {
+				location, _ := c.tree.ErrorContext(arg)
+
+				return fmt.Errorf("%s: %w", location, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkFieldChain checks that the fields can be accessed from a type, the keys of maps aren't checked
+func checkFieldChain(typ reflect.Type, idents []string) error {
+	for _, ident := range idents {
+		if _, ok := typ.MethodByName(ident); ok {
+			return nil
+		}
+
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+
+		switch typ.Kind() { //nolint:exhaustive
+		case reflect.Map, reflect.Interface:
+			return nil
+		case reflect.Struct:
+			field, ok := typ.FieldByName(ident)
+			if !ok || !field.IsExported() {
+				return unknownFieldError(typ, ident)
+			}
+
+			typ = field.Type
+		default:
+			return fmt.Errorf("%w: %s can't have a field %s", errUnknownField, typ, ident)
+		}
+	}
+
+	return nil
+}
+
+func unknownFieldError(typ reflect.Type, ident string) error {
+	best, bestDistance := "", 3
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if distance := levenshtein(strings.ToLower(ident), strings.ToLower(field.Name)); distance < bestDistance {
+			best, bestDistance = field.Name, distance
+		}
+	}
+
+	if best != "" {
+		return fmt.Errorf("%w: %s, did you mean %s?", errUnknownField, ident, best)
+	}
+
+	return fmt.Errorf("%w: %s", errUnknownField, ident)
+}
+
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+
+	for _, v := range values[1:] {
+		if v < result {
+			result = v
+		}
+	}
+
+	return result
+}
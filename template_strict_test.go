@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateStrict(t *testing.T) {
+	bi := &BuildInfo{Version: "1.2.3", Custom: map[string]string{"vendor": "Acme"}}
+	tpl := &ConfigTemplate{Strict: true}
+
+	for _, valid := range []string{
+		"{{ .Version }}",
+		"{{ $.GitBranch | upper }}",
+		"{{ if .GitTag }}{{ .GitTag }}{{ else }}{{ .Version }}{{ end }}",
+		"{{ with .Custom }}{{ .vendor }}{{ end }}",
+		"{{ range $k, $v := .Custom }}{{ $k }}={{ $v }} {{ $.Version }}{{ end }}",
+		"{{ .Custom.vendor }} {{ .Custom.missing }}",
+		"{{ (semver .Version).Major }}",
+	} {
+		_, err := tpl.render(&Config{}, valid, bi)
+		require.NoError(t, err, valid)
+	}
+
+	for template, message := range map[string]string{
+		"{{ .Verison }}":                          "Verison, did you mean Version?",
+		"{{ if .GitTag }}{{ $.Brnch }}{{ end }}":  "unknown build info field: Brnch",
+		"{{ with .Custom }}{{ $.Nope }}{{ end }}": "unknown build info field: Nope",
+		"{{ .Version.Major }}":                    "string can't have a field Major",
+		"{{ .GitBranch | printf \"%s\" .Bad }}":   "unknown build info field: Bad",
+	} {
+		_, err := tpl.render(&Config{}, template, bi)
+		require.ErrorIs(t, err, errUnknownField, template)
+		require.ErrorContains(t, err, message, template)
+	}
+
+	// The partials and the define blocks called with the build info are checked too
+	for _, valid := range []string{
+		`{{ define "v" }}{{ .Version }}{{ end }}{{ template "v" . }}`,
+		`{{ define "c" }}{{ .vendor }}{{ end }}{{ template "c" .Custom }}`,
+		`{{ define "r" }}{{ if .GitTag }}{{ template "r" $ }}{{ end }}{{ end }}{{ template "r" . }}`,
+		`{{ define "unused" }}{{ .Nope }}{{ end }}{{ .Version }}`,
+	} {
+		_, err := tpl.render(&Config{}, valid, bi)
+		require.NoError(t, err, valid)
+	}
+
+	for _, invalid := range []string{
+		`{{ define "v" }}{{ .Verison }}{{ end }}{{ template "v" . }}`,
+		`{{ define "v" }}{{ $.Verison }}{{ end }}{{ with .Custom }}{{ template "v" $ }}{{ end }}`,
+		`{{ define "a" }}{{ template "b" . }}{{ end }}{{ define "b" }}{{ .Verison }}{{ end }}{{ template "a" . }}`,
+	} {
+		_, err := tpl.render(&Config{}, invalid, bi)
+		require.ErrorContains(t, err, "Verison, did you mean Version?", invalid)
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "header.tpl"), []byte("// {{ .PackageNam }}\n"), 0600))
+
+	_, err := tpl.render(&Config{Directory: dir, Partials: []string{"*.tpl"}}, `{{ template "header" . }}`, bi)
+	require.ErrorContains(t, err, "header:1:")
+	require.ErrorContains(t, err, "PackageNam, did you mean PackageName?")
+
+	// Without strict mode, the error is only found when executing the template
+	_, err = (&ConfigTemplate{}).render(&Config{}, "{{ .Verison }}", bi)
+	require.ErrorContains(t, err, "can't evaluate field Verison")
+}
+
+func TestTemplateDelimsAndMissingKey(t *testing.T) {
+	a := require.New(t)
+	bi := &BuildInfo{Version: "1.2.3", Custom: map[string]string{}}
+
+	content, err := (&ConfigTemplate{Delims: []string{"[[", "]]"}}).
+		render(&Config{}, "image: {{ .Values.image }}:[[ .Version ]]", bi)
+	a.NoError(err)
+	a.Equal("image: {{ .Values.image }}:1.2.3", content)
+
+	_, err = (&ConfigTemplate{Delims: []string{"[["}}).render(&Config{}, "", bi)
+	a.ErrorIs(err, errInvalidDelims)
+
+	content, err = (&ConfigTemplate{MissingKey: "zero"}).render(&Config{}, "[{{ .Custom.missing }}]", bi)
+	a.NoError(err)
+	a.Equal("[]", content)
+
+	content, err = (&ConfigTemplate{}).render(&Config{}, "[{{ .Custom.missing }}]", bi)
+	a.NoError(err)
+	a.Equal("[<no value>]", content)
+
+	_, err = (&ConfigTemplate{MissingKey: "error"}).render(&Config{}, "[{{ .Custom.missing }}]", bi)
+	a.ErrorContains(err, `map has no entry for key "missing"`)
+
+	_, err = (&ConfigTemplate{MissingKey: "panic"}).render(&Config{}, "", bi)
+	a.ErrorIs(err, errInvalidMissingKey)
+}
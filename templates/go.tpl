@@ -0,0 +1,33 @@
+{{- $p := option "prefix" "Build" -}}
+// Code generated by ci-info. DO NOT EDIT.
+
+package {{ option "package" "main" | raw }}
+
+var (
+	// {{ $p | raw }}Version is the version of the program
+	{{ $p | raw }}Version = "{{ .Version }}"
+
+	// {{ $p | raw }}Commit is the git commit hash of the program
+	{{ $p | raw }}Commit = "{{ .GitCommitHash }}"
+
+	// {{ $p | raw }}CommitShort is the short git commit hash of the program
+	{{ $p | raw }}CommitShort = "{{ .GitCommitHashShort }}"
+
+	// {{ $p | raw }}CommitDate is the date of the git commit
+	{{ $p | raw }}CommitDate = "{{ .GitCommitDate }}"
+
+	// {{ $p | raw }}Branch is the git branch the program was built from
+	{{ $p | raw }}Branch = "{{ .GitBranch }}"
+
+	// {{ $p | raw }}Tag is the git tag the program was built from
+	{{ $p | raw }}Tag = "{{ .GitTag }}"
+
+	// {{ $p | raw }}Ref is the git tag, or the branch and commit the program was built from
+	{{ $p | raw }}Ref = "{{ .GitSmartRef }}"
+
+	// {{ $p | raw }}Date is the time the program was built
+	{{ $p | raw }}Date = "{{ .BuildDate }}"
+
+	// {{ $p | raw }}Number is the number of the CI build
+	{{ $p | raw }}Number = "{{ .CIBuildNumber }}"
+)
@@ -0,0 +1,27 @@
+{{- $v := semver .VersionNumeric -}}
+{{- $product := option "product" .PackageName -}}
+{
+  "FixedFileInfo": {
+    "FileVersion": { "Major": {{ $v.Major }}, "Minor": {{ $v.Minor }}, "Patch": {{ $v.Patch }}, "Build": {{ $v.Revision }} },
+    "ProductVersion": { "Major": {{ $v.Major }}, "Minor": {{ $v.Minor }}, "Patch": {{ $v.Patch }}, "Build": {{ $v.Revision }} },
+    "FileFlagsMask": "3f",
+    "FileFlags": "{{ if eq .Version .VersionCore }}00{{ else }}02{{ end }}",
+    "FileOS": "040004",
+    "FileType": "01",
+    "FileSubType": "00"
+  },
+  "StringFileInfo": {
+    "Comments": "Generated by ci-info",
+    "CompanyName": "{{ option "company" "" }}",
+    "FileDescription": "{{ option "description" $product }}",
+    "FileVersion": "{{ .VersionNumeric }}",
+    "InternalName": "{{ option "internal_name" $product }}",
+    "LegalCopyright": "{{ option "copyright" "" }}",
+    "OriginalFilename": "{{ option "original_filename" "" }}",
+    "ProductName": "{{ $product }}",
+    "ProductVersion": "{{ .Version }}"
+  },
+  "VarFileInfo": {
+    "Translation": { "LangID": "0409", "CharsetID": "04B0" }
+  }
+}
@@ -0,0 +1,18 @@
+defmodule Acme.MixProject do
+  use Mix.Project
+
+  @version "0.12.1"
+
+  def project do
+    [
+      app: :acme,
+      version: @version,
+      elixir: "~> 1.15",
+      deps: deps()
+    ]
+  end
+
+  defp deps do
+    []
+  end
+end
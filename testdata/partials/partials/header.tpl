@@ -0,0 +1 @@
+{{ define "header" }}Generated by ci-info for version {{ .Version }}{{ end }}
@@ -0,0 +1 @@
+{{- define "license" }}Licensed under the MIT license{{ end -}}
@@ -0,0 +1 @@
+{{ template "header" . }}
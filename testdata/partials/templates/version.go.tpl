@@ -0,0 +1,5 @@
+// {{ template "header" . }}
+// {{ template "license" }}
+package version
+
+const Version = "{{ .Version }}"
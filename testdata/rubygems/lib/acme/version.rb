@@ -0,0 +1,5 @@
+# frozen_string_literal: true
+
+module Acme
+  VERSION = "0.9.2"
+end
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+var errInvalidVariable = errors.New("invalid variable, expected key=value")
+
+// variablesFlag is a repeatable "key=value" command line flag
+type variablesFlag map[string]string
+
+func (f variablesFlag) String() string {
+	pairs := make([]string, 0, len(f))
+
+	for key, value := range f {
+		pairs = append(pairs, key+"="+value)
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+func (f variablesFlag) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("%w: %s", errInvalidVariable, s)
+	}
+
+	f[key] = value
+
+	return nil
+}
+
+// loadVariables exposes the allowed environment variables and renders the custom variables of the config
+func (bi *BuildInfo) loadVariables(config *Config) error {
+	for _, name := range config.EnvVars {
+		if value, ok := os.LookupEnv(name); ok {
+			if bi.Env == nil {
+				bi.Env = map[string]string{}
+			}
+
+			bi.Env[name] = value
+		}
+	}
+
+	custom, err := renderVariables(config.Variables, bi)
+	if err != nil {
+		return err
+	}
+
+	bi.Custom = custom
+
+	return nil
+}
+
+// env returns an allowed environment variable, the other ones being empty: {{ env "DEPLOY_ENV" }}
+func (bi *BuildInfo) env(name string) string {
+	return bi.Env[name]
+}
+
+// withVariables returns a copy of the build info with the variables of a template on top of the custom ones
+func (bi *BuildInfo) withVariables(variables map[string]string) (*BuildInfo, error) {
+	if len(variables) == 0 {
+		return bi, nil
+	}
+
+	rendered, err := renderVariables(variables, bi)
+	if err != nil {
+		return nil, err
+	}
+
+	result := *bi
+	result.Custom = make(map[string]string, len(bi.Custom)+len(rendered))
+
+	for key, value := range bi.Custom {
+		result.Custom[key] = value
+	}
+
+	for key, value := range rendered {
+		result.Custom[key] = value
+	}
+
+	return &result, nil
+}
+
+// renderVariables renders the values of the variables, which are templates over the build info
+func renderVariables(variables map[string]string, bi *BuildInfo) (map[string]string, error) {
+	if len(variables) == 0 {
+		return nil, nil
+	}
+
+	rendered := make(map[string]string, len(variables))
+
+	for key, value := range variables {
+		result, err := renderTemplate(value, bi)
+		if err != nil {
+			return nil, fmt.Errorf("could not render variable %s: %w", key, err)
+		}
+
+		rendered[key] = result
+	}
+
+	return rendered, nil
+}
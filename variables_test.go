@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariables(t *testing.T) {
+	a := require.New(t)
+
+	t.Setenv("DEPLOY_ENV", "staging")
+	t.Setenv("SECRET_TOKEN", "secret")
+
+	config := &Config{
+		Variables: map[string]string{
+			"product": "Acme {{ .Version }}",
+			"env":     "{{ .Env.DEPLOY_ENV }}",
+		},
+		EnvVars: []string{"DEPLOY_ENV", "NOT_DEFINED"},
+	}
+
+	bi := &BuildInfo{Version: "1.2.3"}
+	a.NoError(bi.loadVariables(config))
+	a.Equal(map[string]string{"DEPLOY_ENV": "staging"}, bi.Env)
+	a.Equal(map[string]string{"product": "Acme 1.2.3", "env": "staging"}, bi.Custom)
+
+	// Only the allowed environment variables are saved
+	b, err := json.Marshal(bi)
+	a.NoError(err)
+	a.Contains(string(b), `"custom":{"env":"staging","product":"Acme 1.2.3"}`)
+	a.NotContains(string(b), "secret")
+
+	// The variables of a template override the global ones, for this template only
+	tpl := &ConfigTemplate{Variables: map[string]string{"product": "Acme Pro {{ .Version }}"}}
+	content, err := tpl.render(config, "{{ .Custom.product }} in {{ .Custom.env }}", bi)
+	a.NoError(err)
+	a.Equal("Acme Pro 1.2.3 in staging", content)
+	a.Equal("Acme 1.2.3", bi.Custom["product"])
+
+	a.Error(bi.loadVariables(&Config{Variables: map[string]string{"bad": "{{ .Unknown }}"}}))
+}
+
+func TestVariablesFlag(t *testing.T) {
+	a := require.New(t)
+
+	params, err := getParams([]string{"-set", "vendor=Acme", "--set", "year=2022"})
+	a.NoError(err)
+	a.Equal(variablesFlag{"vendor": "Acme", "year": "2022"}, params.Variables)
+	a.Equal("vendor=Acme,year=2022", params.Variables.String())
+
+	_, err = getParams([]string{"-set", "vendor"})
+	a.ErrorContains(err, errInvalidVariable.Error())
+}
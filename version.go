@@ -0,0 +1,198 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidVersion is returned when a version can't be parsed
+var ErrInvalidVersion = errors.New("invalid version")
+
+var reSemVersion = regexp.MustCompile(
+	`^[vV]?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.\-]+))?(?:\+([0-9A-Za-z.\-]+))?$`,
+)
+
+// semVersion is a parsed semantic version. The parsing is lenient: the minor and patch
+// components can be omitted, and a fourth numeric component (.NET, Windows) is accepted.
+type semVersion struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Revision   int
+	Prerelease string
+	Metadata   string
+}
+
+func parseVersion(version string) (*semVersion, error) {
+	matches := reSemVersion.FindStringSubmatch(strings.TrimSpace(version))
+	if matches == nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidVersion, version)
+	}
+
+	v := &semVersion{
+		Prerelease: matches[5],
+		Metadata:   matches[6],
+	}
+
+	for i, target := range []*int{&v.Major, &v.Minor, &v.Patch, &v.Revision} {
+		if matches[i+1] == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(matches[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidVersion, version)
+		}
+
+		*target = n
+	}
+
+	return v, nil
+}
+
+// Core returns the major.minor.patch part of the version
+func (v *semVersion) Core() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+func (v *semVersion) String() string {
+	s := v.Core()
+
+	if v.Revision != 0 {
+		s += "." + strconv.Itoa(v.Revision)
+	}
+
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+
+	if v.Metadata != "" {
+		s += "+" + v.Metadata
+	}
+
+	return s
+}
+
+// maxVersionComponent is the highest value of the 16 bits components of the Windows versions
+const maxVersionComponent = 65535
+
+// loadNumericVersion fills the strictly numeric versions required by the Windows resources and the Apple bundles,
+// from the declared version and the build number: 0.1.0-feature-x-6ea1772 built by the CI build 45 gives 0.1.0
+// and 0.1.0.45
+func (bi *BuildInfo) loadNumericVersion() {
+	v, err := parseVersion(bi.VersionDeclared)
+	if err != nil {
+		if v, err = parseVersion(bi.Version); err != nil {
+			v = &semVersion{}
+		}
+	}
+
+	build := v.Revision
+
+	for _, number := range []string{bi.BuildNumber, bi.CIBuildNumber} {
+		if build != 0 {
+			break
+		}
+
+		if n, err := strconv.Atoi(number); err == nil && n > 0 {
+			build = n
+		}
+	}
+
+	bi.VersionCore = v.Core()
+	bi.VersionBuild = strconv.Itoa(build)
+
+	// Some CI build numbers (GitHub Actions run IDs) don't fit in a Windows version component
+	if build > maxVersionComponent {
+		log.Debug("The build number doesn't fit in a numeric version, it's ignored", "build", build)
+
+		build = 0
+	}
+
+	bi.VersionNumeric = fmt.Sprintf("%d.%d.%d.%d", clampVersionComponent(v.Major), clampVersionComponent(v.Minor),
+		clampVersionComponent(v.Patch), build)
+}
+
+func clampVersionComponent(n int) int {
+	if n > maxVersionComponent {
+		return maxVersionComponent
+	}
+
+	return n
+}
+
+// normalizeVersion returns a version that can be compared with other ones: "v2.3" and "2.3.0+45" both become "2.3.0"
+func normalizeVersion(version string) string {
+	v, err := parseVersion(version)
+	if err != nil {
+		return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(version)), "v")
+	}
+
+	v.Metadata = ""
+
+	return v.String()
+}
+
+// compare returns -1, 0 or 1 following the semantic versioning precedence rules
+func (v *semVersion) compare(other *semVersion) int {
+	for _, pair := range [][2]int{
+		{v.Major, other.Major},
+		{v.Minor, other.Minor},
+		{v.Patch, other.Patch},
+		{v.Revision, other.Revision},
+	} {
+		if pair[0] != pair[1] {
+			return compareInts(pair[0], pair[1])
+		}
+	}
+
+	// A pre-release version has a lower precedence than the associated normal version
+	switch {
+	case v.Prerelease == other.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case other.Prerelease == "":
+		return -1
+	}
+
+	ids, otherIds := strings.Split(v.Prerelease, "."), strings.Split(other.Prerelease, ".")
+
+	for i := 0; i < len(ids) && i < len(otherIds); i++ {
+		if c := comparePrereleaseIdentifiers(ids[i], otherIds[i]); c != 0 {
+			return c
+		}
+	}
+
+	return compareInts(len(ids), len(otherIds))
+}
+
+func comparePrereleaseIdentifiers(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+
+	switch {
+	case errA == nil && errB == nil:
+		return compareInts(na, nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
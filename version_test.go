@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	a := require.New(t)
+
+	v, err := parseVersion("v1.2.3-rc.1+45")
+	a.NoError(err)
+	a.Equal(&semVersion{Major: 1, Minor: 2, Patch: 3, Prerelease: "rc.1", Metadata: "45"}, v)
+	a.Equal("1.2.3-rc.1+45", v.String())
+	a.Equal("1.2.3", v.Core())
+
+	v, err = parseVersion("2.4.0.7")
+	a.NoError(err)
+	a.Equal(7, v.Revision)
+
+	_, err = parseVersion("main")
+	a.ErrorIs(err, ErrInvalidVersion)
+}
+
+func TestNormalizeVersion(t *testing.T) {
+	a := require.New(t)
+
+	a.Equal("2.3.0", normalizeVersion("v2.3"))
+	a.Equal("2.3.0", normalizeVersion("2.3.0+45"))
+	a.Equal("2.4.0", normalizeVersion("2.4.0.0"))
+	a.Equal("2.3.0-beta1", normalizeVersion("V2.3.0-beta1"))
+	a.Equal("main", normalizeVersion("main"))
+}
+
+func TestCompareVersions(t *testing.T) {
+	a := require.New(t)
+
+	for _, test := range []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.2.4", -1},
+		{"2.0.0", "1.9.9", 1},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-beta.11", "1.0.0-beta.2", 1},
+		{"1.0.0+45", "1.0.0+46", 0},
+	} {
+		va, err := parseVersion(test.a)
+		a.NoError(err)
+		vb, err := parseVersion(test.b)
+		a.NoError(err)
+		a.Equal(test.expected, va.compare(vb), "%s <=> %s", test.a, test.b)
+	}
+}